@@ -0,0 +1,122 @@
+package options
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/muesli/reflow/ansi"
+)
+
+// SetAccessible sets whether the picker renders for screen readers, as
+// described on Accessible.
+func (m *Model) SetAccessible(v bool) {
+	m.Invalidate()
+	m.Accessible = v
+	m.announcement = ""
+}
+
+// Announcement returns a line of plain text describing what the last update
+// changed, such as the option the cursor moved onto, for a speech layer to
+// read out. It's empty when nothing worth announcing changed, and always
+// while Accessible isn't set.
+func (m Model) Announcement() string {
+	return m.announcement
+}
+
+// accessibleMarks returns the words spelling out the state of row r, given
+// the row the cursor is on, each followed by a space.
+func (m Model) accessibleMarks(r, cursor int, item Option) string {
+	if !m.Accessible {
+		return ""
+	}
+	var marks string
+	if r == cursor {
+		marks += "(selected) "
+	}
+	if item.Disabled {
+		marks += "(unavailable) "
+	}
+	return marks
+}
+
+// stripEscapes returns s without the escape sequences in it, for the view
+// to be plain text.
+func stripEscapes(s string) string {
+	if !strings.ContainsRune(s, ansi.Marker) {
+		return s
+	}
+	var (
+		b      strings.Builder
+		escape bool
+	)
+	b.Grow(len(s))
+	for _, r := range s {
+		switch {
+		case r == ansi.Marker:
+			escape = true
+		case escape:
+			escape = !ansi.IsTerminator(r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// announced is the state of the picker that's announced when it changes.
+type announced struct {
+	option  int
+	checked bool
+	matches int
+}
+
+// announced returns the state of the picker to announce changes to.
+func (m Model) announced() announced {
+	i := m.optionIndex(m.cursorIndex())
+	a := announced{option: i, checked: m.Checked(i), matches: -1}
+	if m.filterActive() {
+		a.matches = len(m.filtered)
+	}
+	return a
+}
+
+// announce sets the announcement to what changed since the state was
+// before.
+func (m *Model) announce(before announced) {
+	m.announcement = ""
+	if !m.Accessible {
+		return
+	}
+	after := m.announced()
+	var parts []string
+	if after.matches != before.matches && after.matches != -1 {
+		switch after.matches {
+		case 0:
+			parts = append(parts, "No matches")
+		case 1:
+			parts = append(parts, "1 match")
+		default:
+			parts = append(parts, strconv.Itoa(after.matches)+" matches")
+		}
+	}
+	switch i := after.option; {
+	case i == -1:
+	case i != before.option:
+		s := singleLine(m.Options[i]) + ", " + strconv.Itoa(m.cursorIndex()+1) + " of " + strconv.Itoa(m.rowCount())
+		if m.checkable(i) && m.SelectionMode != SelectOne {
+			s += ", " + checkedWord(after.checked)
+		}
+		parts = append(parts, s)
+	case after.checked != before.checked:
+		parts = append(parts, singleLine(m.Options[i])+" "+checkedWord(after.checked))
+	}
+	m.announcement = strings.Join(parts, ". ")
+}
+
+// checkedWord returns how an option checked or not is announced.
+func checkedWord(checked bool) string {
+	if checked {
+		return "checked"
+	}
+	return "not checked"
+}
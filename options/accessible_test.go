@@ -0,0 +1,116 @@
+package options
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/muesli/reflow/ansi"
+)
+
+// newAccessibleModel returns a picker in the accessible mode, rendering in
+// colors it's then expected to leave out.
+func newAccessibleModel(items []Option) Model {
+	m := newColorModel(WithItems(items))
+	m.SelectionMode = SelectMany
+	m.SetAccessible(true)
+	resize(&m, 40, 10)
+	return m
+}
+
+// errTest is the error of the Validate functions of the tests.
+var errTest = errors.New("pick another")
+
+var accessibleItems = []Option{{Label: "apple"}, {Label: "pear", Disabled: true}, {Label: "plum"}}
+
+func TestAccessibleView(t *testing.T) {
+	m := newAccessibleModel(accessibleItems)
+	want := "> [ ] (selected) apple\n  [ ] (unavailable) pear\n  [ ] plum"
+	if got := m.View(); got != want {
+		t.Errorf("view:\n%q\nwant:\n%q", got, want)
+	}
+	press(&m, "down", " ")
+	want = "  [ ] apple\n  [ ] (unavailable) pear\n> [x] (selected) plum"
+	if got := m.View(); got != want {
+		t.Errorf("view after checking plum:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestAccessibleViewPlain(t *testing.T) {
+	m := newAccessibleModel(accessibleItems)
+	m.Title = "Fruit"
+	m.Bordered = true
+	m.ShowStatusBar = true
+	m.ShowScrollbar = true
+	press(&m, "down", " ", "/", "p")
+	view := m.View()
+	if strings.ContainsRune(view, ansi.Marker) {
+		t.Errorf("view holds escape sequences:\n%q", view)
+	}
+	for i := 0; i < 3; i++ {
+		if again := m.View(); again != view {
+			t.Fatalf("view differs when rendered again:\n%q\nthen:\n%q", view, again)
+		}
+	}
+	press(&m, "enter")
+	f := NewField(m)
+	f.Validate = func(Selection) error { return errTest }
+	f, _ = f.Update(keyMsg("enter"))
+	if view := f.View(); strings.ContainsRune(view, ansi.Marker) || !strings.Contains(view, errTest.Error()) {
+		t.Errorf("field view with an error:\n%q", view)
+	}
+}
+
+func TestAnnouncements(t *testing.T) {
+	steps := []struct {
+		keys []string
+		want string
+	}{
+		{[]string{"down"}, "plum, 3 of 3, not checked"},
+		{[]string{" "}, "plum checked"},
+		{[]string{" "}, "plum not checked"},
+		{[]string{"up"}, "apple, 1 of 3, not checked"},
+		{[]string{"up"}, ""},
+		{[]string{"/", "p"}, "2 matches. plum, 1 of 2, not checked"},
+		{[]string{"l"}, ""},
+		{[]string{"u"}, "1 match"},
+		{[]string{"z"}, "No matches"},
+	}
+	m := newAccessibleModel(accessibleItems)
+	for _, s := range steps {
+		press(&m, s.keys...)
+		if got := m.Announcement(); got != s.want {
+			t.Errorf("after %q: announced %q, want %q", s.keys, got, s.want)
+		}
+	}
+}
+
+func TestAnnouncementsOnlyWhenAccessible(t *testing.T) {
+	m := newAccessibleModel(accessibleItems)
+	press(&m, "down")
+	if m.Announcement() == "" {
+		t.Fatal("nothing announced")
+	}
+	m.SetAccessible(false)
+	if got := m.Announcement(); got != "" {
+		t.Errorf("announcement %q left after leaving the accessible mode", got)
+	}
+	press(&m, "up")
+	if got := m.Announcement(); got != "" {
+		t.Errorf("announced %q outside the accessible mode", got)
+	}
+	if view := m.View(); !strings.ContainsRune(view, ansi.Marker) || strings.Contains(view, "(selected)") {
+		t.Errorf("view outside the accessible mode:\n%q", view)
+	}
+}
+
+func TestAccessibleFromEnvironment(t *testing.T) {
+	t.Setenv("ACCESSIBLE", "1")
+	if m := New(); !m.Accessible {
+		t.Error("not accessible with ACCESSIBLE set")
+	}
+	t.Setenv("ACCESSIBLE", "")
+	if m := New(); m.Accessible {
+		t.Error("accessible with ACCESSIBLE empty")
+	}
+}
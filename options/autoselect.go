@@ -0,0 +1,81 @@
+package options
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type autoSelectMsg struct {
+	id  int
+	seq int
+}
+
+// autoSelecting returns whether the countdown to selecting AutoSelect is on.
+func (m Model) autoSelecting() bool {
+	return m.AutoSelectAfter > 0 && !m.autoSelectStopped
+}
+
+// autoSelectLeft returns the time left until AutoSelect is selected.
+func (m Model) autoSelectLeft() time.Duration {
+	return max(m.AutoSelectAfter-m.autoSelectElapsed, 0)
+}
+
+// autoSelectTick returns the command counting down to selecting AutoSelect,
+// a second at a time.
+func (m Model) autoSelectTick() tea.Cmd {
+	id, seq := m.id, m.autoSelectSeq
+	return tea.Tick(min(m.autoSelectLeft(), time.Second), func(time.Time) tea.Msg {
+		return autoSelectMsg{id: id, seq: seq}
+	})
+}
+
+// stopAutoSelect stops the countdown, for good.
+func (m *Model) stopAutoSelect() {
+	m.autoSelectStopped = true
+	m.autoSelectSeq++
+}
+
+// autoSelectDue returns whether msg ends the countdown, selecting
+// AutoSelect.
+func (m Model) autoSelectDue(msg tea.Msg) bool {
+	tick, ok := msg.(autoSelectMsg)
+	if !ok || tick.id != m.id || tick.seq != m.autoSelectSeq || !m.autoSelecting() {
+		return false
+	}
+	r := m.rowOf(m.AutoSelect)
+	return m.autoSelectLeft() <= time.Second && r != -1 && m.selectable(r) && m.selects(r)
+}
+
+// handleAutoSelect counts down a tick, selecting AutoSelect once the time is
+// up as the Select key would. It's stopped instead if the option can't be
+// selected by then.
+func (m *Model) handleAutoSelect(msg autoSelectMsg) tea.Cmd {
+	if msg.id != m.id || msg.seq != m.autoSelectSeq || !m.autoSelecting() {
+		return nil
+	}
+	if m.autoSelectLeft() > time.Second {
+		m.autoSelectElapsed += time.Second
+		return m.autoSelectTick()
+	}
+	m.stopAutoSelect()
+	if m.rowOf(m.AutoSelect) == -1 || !m.selects(m.rowOf(m.AutoSelect)) {
+		return nil
+	}
+	m.SetSelected(m.AutoSelect)
+	if m.Index() != m.AutoSelect {
+		return nil
+	}
+	return m.choose()
+}
+
+// autoSelectView renders the countdown to selecting AutoSelect.
+func (m Model) autoSelectView() string {
+	label := ""
+	if m.AutoSelect >= 0 && m.AutoSelect < len(m.Options) {
+		label = m.Options[m.AutoSelect]
+	}
+	left := wholeSeconds(m.autoSelectLeft())
+	return m.Styles.StatusMessage.Render(fmt.Sprintf(m.AutoSelectMessage, singleLine(label), left))
+}
@@ -0,0 +1,90 @@
+package options
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const defaultCursorBlinkInterval = 500 * time.Millisecond
+
+type cursorBlinkMsg struct {
+	id  int
+	seq int
+}
+
+// Focus focuses the picker. The returned command starts blinking the cursor
+// when CursorBlink is set.
+func (m *Model) Focus() tea.Cmd {
+	m.Invalidate()
+	m.blurred = false
+	return m.startBlink()
+}
+
+// Blur blurs the picker. A blurred picker ignores key presses and draws its
+// cursor with Styles.DisabledCursor, without blinking, and its rows with
+// Styles.Blurred when DimBlurred is set.
+func (m *Model) Blur() {
+	m.Invalidate()
+	m.blurred = true
+	m.cursorHidden = false
+}
+
+// Focused returns whether the picker is focused. New pickers are.
+func (m Model) Focused() bool {
+	return !m.blurred
+}
+
+// cursorStyle returns the style of the cursor, which depends on whether the
+// picker is focused.
+func (m Model) cursorStyle() lipgloss.Style {
+	if m.blurred {
+		return m.Styles.DisabledCursor
+	}
+	return m.Styles.Cursor
+}
+
+// startBlink returns the command that blinks the cursor, replacing any
+// blinking already going on, or nil if the cursor doesn't blink.
+func (m *Model) startBlink() tea.Cmd {
+	m.blinkSeq++
+	m.cursorHidden = false
+	if !m.CursorBlink || m.blurred {
+		return nil
+	}
+	return m.blinkTick()
+}
+
+// blinkTick returns the command that toggles the cursor once the blink
+// interval has passed.
+func (m Model) blinkTick() tea.Cmd {
+	id, seq := m.id, m.blinkSeq
+	return tea.Tick(m.CursorBlinkInterval, func(time.Time) tea.Msg {
+		return cursorBlinkMsg{id: id, seq: seq}
+	})
+}
+
+// handleCursorBlink shows or hides the cursor, unless the blinking msg is
+// part of has stopped since.
+func (m *Model) handleCursorBlink(msg cursorBlinkMsg) tea.Cmd {
+	if msg.id != m.id || msg.seq != m.blinkSeq {
+		return nil
+	}
+	if !m.CursorBlink || m.blurred {
+		m.cursorHidden = false
+		return nil
+	}
+	m.cursorHidden = !m.cursorHidden
+	return m.blinkTick()
+}
+
+// cursorView returns the cursor, or as many spaces while it blinks off. It
+// doesn't blink when the renderer can't show colors.
+func (m Model) cursorView() string {
+	if m.cursorHidden && !m.plain() {
+		return strings.Repeat(" ", stringWidth(m.Cursor))
+	}
+	return m.Cursor
+}
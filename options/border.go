@@ -0,0 +1,62 @@
+package options
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// contentWidth returns the number of cells the picker may take up inside its
+// border when Bordered, or Width when it isn't. It's zero when the width is
+// unbounded.
+func (m Model) contentWidth() int {
+	if m.Width <= 0 || !m.Bordered {
+		return m.Width
+	}
+	return max(m.Width-m.Styles.Border.GetHorizontalFrameSize(), 1)
+}
+
+// borderedView renders the view of the picker inside Styles.Border, with the
+// title set in its top edge.
+func (m Model) borderedView() string {
+	inner := m
+	inner.Bordered, inner.Title = false, ""
+	inner.Width = m.contentWidth()
+	body := inner.view()
+
+	b := m.Styles.Border.Copy().BorderTop(false)
+	if m.Width > 0 {
+		b = b.Width(m.Width - b.GetHorizontalBorderSize() - b.GetHorizontalMargins())
+	}
+	framed := b.Render(body)
+	width := lipgloss.Width(framed) - b.GetHorizontalMargins()
+	top := strings.Repeat(" ", b.GetMarginLeft()) + m.topEdge(width)
+	return strings.Repeat("\n", b.GetMarginTop()) + top + "\n" +
+		strings.TrimPrefix(framed, strings.Repeat("\n", b.GetMarginTop()))
+}
+
+// topEdge renders the top edge of the border, width cells wide, with the
+// title set in it after the first cell of the edge.
+func (m Model) topEdge(width int) string {
+	border := m.Styles.Border.GetBorderStyle()
+	r := m.renderer
+	if r == nil {
+		r = lipgloss.DefaultRenderer()
+	}
+	edge := r.NewStyle().
+		Foreground(m.Styles.Border.GetBorderTopForeground()).
+		Background(m.Styles.Border.GetBorderTopBackground())
+
+	fill := max(width-lipgloss.Width(border.TopLeft)-lipgloss.Width(border.TopRight), 0)
+	title := ""
+	if room := fill - 2 - m.Styles.Title.GetHorizontalFrameSize(); m.Title != "" && room > 0 {
+		text, _ := truncate(singleLine(m.Title), room, m.Ellipsis)
+		title = m.zone("title", m.Styles.Title.Render(text))
+	}
+	if title == "" {
+		return edge.Render(border.TopLeft + strings.Repeat(border.Top, fill) + border.TopRight)
+	}
+	rest := max(fill-1-lipgloss.Width(title), 0)
+	return edge.Render(border.TopLeft+border.Top) + title +
+		edge.Render(strings.Repeat(border.Top, rest)+border.TopRight)
+}
@@ -0,0 +1,159 @@
+package options
+
+import (
+	"errors"
+	"fmt"
+)
+
+// OptionOpt sets up an option added with a Builder.
+type OptionOpt func(*Option)
+
+// WithValue sets the value identifying the option.
+func WithValue(v string) OptionOpt {
+	return func(o *Option) {
+		o.Value = v
+	}
+}
+
+// WithDescription sets the text describing the option.
+func WithDescription(d string) OptionOpt {
+	return func(o *Option) {
+		o.Description = d
+	}
+}
+
+// WithIcon sets the icon shown before the label of the option.
+func WithIcon(icon string) OptionOpt {
+	return func(o *Option) {
+		o.Icon = icon
+	}
+}
+
+// WithShortcut sets the key that selects the option.
+func WithShortcut(k string) OptionOpt {
+	return func(o *Option) {
+		o.Shortcut = k
+	}
+}
+
+// WithDisabled greys the option out so that it can't hold the cursor.
+func WithDisabled() OptionOpt {
+	return func(o *Option) {
+		o.Disabled = true
+	}
+}
+
+// WithHidden leaves the option out of the list, for it to be selected by
+// value only.
+func WithHidden() OptionOpt {
+	return func(o *Option) {
+		o.Hidden = true
+	}
+}
+
+// WithChecked checks the option initially.
+func WithChecked() OptionOpt {
+	return func(o *Option) {
+		o.Checked = true
+	}
+}
+
+// WithCollapsed collapses a group initially.
+func WithCollapsed() OptionOpt {
+	return func(o *Option) {
+		o.Collapsed = true
+	}
+}
+
+// WithExpanded shows the options of a submenu initially.
+func WithExpanded() OptionOpt {
+	return func(o *Option) {
+		o.Expanded = true
+	}
+}
+
+// Builder assembles the structured entries of a menu, to be checked and
+// returned by Build:
+//
+//	items, err := options.NewBuilder().
+//		Group("Files").
+//		Option("Open", options.WithShortcut("o")).
+//		Separator().
+//		Submenu("Recent", options.NewBuilder().Option("notes.txt")).
+//		Build()
+type Builder struct {
+	items []Option
+	errs  []error
+}
+
+// NewBuilder returns an empty Builder.
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// Option adds a selectable option labelled label.
+func (b *Builder) Option(label string, opts ...OptionOpt) *Builder {
+	return b.add(Option{Label: label}, opts)
+}
+
+// Info adds an informational row, which the cursor skips.
+func (b *Builder) Info(label string, opts ...OptionOpt) *Builder {
+	return b.add(Option{Label: label, Kind: Info}, opts)
+}
+
+// Group starts a group of the options added after it, up to the next group.
+func (b *Builder) Group(label string, opts ...OptionOpt) *Builder {
+	return b.add(Option{Label: label, Kind: Header}, opts)
+}
+
+// Separator adds a blank informational row.
+func (b *Builder) Separator() *Builder {
+	return b.add(Option{Kind: Info}, nil)
+}
+
+// Submenu adds an option labelled label holding the options of sub as its
+// children, shown in a tree.
+func (b *Builder) Submenu(label string, sub *Builder, opts ...OptionOpt) *Builder {
+	if sub == nil || len(sub.items) == 0 {
+		b.errs = append(b.errs[:len(b.errs):len(b.errs)], fmt.Errorf("options: submenu %q has no options", label))
+		return b.add(Option{Label: label}, opts)
+	}
+	b.errs = append(b.errs[:len(b.errs):len(b.errs)], sub.errs...)
+	return b.add(Option{Label: label, Children: sub.items}, opts)
+}
+
+func (b *Builder) add(o Option, opts []OptionOpt) *Builder {
+	for _, opt := range opts {
+		opt(&o)
+	}
+	// Builders are shared by the options they're added to, so don't append
+	// to their entries in place.
+	b.items = append(b.items[:len(b.items):len(b.items)], o)
+	return b
+}
+
+// Build returns the entries added, ready for Model.SetItems, or the errors
+// found in them: shortcuts used by more than one option and submenus
+// without options.
+func (b *Builder) Build() ([]Option, error) {
+	errs := append([]error(nil), b.errs...)
+	shortcuts := make(map[string]string)
+	var walk func(items []Option)
+	walk = func(items []Option) {
+		for _, item := range items {
+			if item.Shortcut != "" {
+				if other, ok := shortcuts[item.Shortcut]; ok {
+					errs = append(errs, fmt.Errorf("options: shortcut %q of %q is taken by %q", item.Shortcut, item.Label, other))
+				} else {
+					shortcuts[item.Shortcut] = item.Label
+				}
+			}
+			walk(item.Children)
+		}
+	}
+	walk(b.items)
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return b.items, nil
+}
@@ -0,0 +1,137 @@
+package options
+
+import "sync"
+
+// viewCache holds the last view rendered by a model and its copies.
+type viewCache struct {
+	mu   sync.Mutex
+	key  viewKey
+	view string
+	ok   bool
+
+	// gen is the last generation handed out to the copies of the model, so
+	// that copies that change apart never share one.
+	gen int
+
+	// rows holds the rows rendered off the cursor for the generation
+	// rowsGen, which are reused while only the cursor moves.
+	rowsMu  sync.Mutex
+	rows    map[rowKey]string
+	rowsGen int
+}
+
+// rowKey tells apart the rows cached for a generation.
+type rowKey struct {
+	r, i  int
+	width int
+}
+
+// rowCacheMargin is the number of rows cached beyond those in the window, for
+// the ones scrolled past to be reused when scrolling back.
+const rowCacheMargin = 32
+
+// viewKey holds the state the view is rendered from, to tell whether a cached
+// view is still current. Anything else that changes the view bumps gen.
+type viewKey struct {
+	gen           int
+	selected      int
+	min, size     int
+	width, height int
+	filterState   FilterState
+	filterValue   string
+	filterPos     int
+	filterFocused bool
+	filterBlink   bool
+}
+
+// viewKey returns the key of the view of the model in its current state.
+func (m Model) viewKey() viewKey {
+	return viewKey{
+		gen:           m.gen,
+		selected:      m.selected,
+		min:           m.min,
+		size:          m.size,
+		width:         m.Width,
+		height:        m.Height,
+		filterState:   m.filterState,
+		filterValue:   m.FilterInput.Value(),
+		filterPos:     m.FilterInput.Position(),
+		filterFocused: m.FilterInput.Focused(),
+		filterBlink:   m.FilterInput.Cursor.Blink,
+	}
+}
+
+// cachedView returns the view from the cache, rendering it with render when
+// the cache is off or out of date.
+func (m Model) cachedView(render func() string) string {
+	if !m.CacheView || m.cache == nil {
+		return render()
+	}
+	key := m.viewKey()
+	m.cache.mu.Lock()
+	defer m.cache.mu.Unlock()
+	if !m.cache.ok || m.cache.key != key {
+		m.cache.key, m.cache.view, m.cache.ok = key, render(), true
+	}
+	return m.cache.view
+}
+
+// Invalidate marks the cached view as out of date. Call it after changing the
+// fields of the model directly while CacheView is set, the options are laid
+// out in a grid or the status bar is shown, which look at the rows once; the
+// methods of the model and Update do so themselves.
+func (m *Model) Invalidate() {
+	m.cellWidthOK, m.selectableOK = false, false
+	if m.cache == nil {
+		return
+	}
+	m.cache.mu.Lock()
+	defer m.cache.mu.Unlock()
+	m.cache.gen++
+	m.gen = m.cache.gen
+	m.rowGen = m.gen
+}
+
+// invalidateSize marks the cached view as out of date after the picker is
+// resized, keeping what Invalidate drops of the rows measured, which the
+// size leaves as they are.
+func (m *Model) invalidateSize() {
+	ok, selectable := m.cellWidthOK, m.selectableOK
+	m.Invalidate()
+	m.cellWidthOK, m.selectableOK = ok, selectable
+}
+
+// invalidateCursor marks the cached view as out of date after the cursor has
+// moved, the window has scrolled or the cursor has blinked, but keeps the
+// cached rows, since only the row on the cursor changes.
+func (m *Model) invalidateCursor() {
+	if m.cache == nil {
+		return
+	}
+	m.cache.mu.Lock()
+	defer m.cache.mu.Unlock()
+	m.cache.gen++
+	m.gen = m.cache.gen
+}
+
+// cachedRow returns row r, showing the option at index i of Options off the
+// cursor, from the cache, rendering it with render when the cache is off or
+// doesn't hold it. The cache is emptied once it holds more rows than fit in
+// the window and the margin, so that it doesn't grow with the list.
+func (m Model) cachedRow(r, i, width int, render func() string) string {
+	if !m.CacheView || m.cache == nil {
+		return render()
+	}
+	key := rowKey{r: r, i: i, width: width}
+	m.cache.rowsMu.Lock()
+	defer m.cache.rowsMu.Unlock()
+	if m.cache.rows == nil || m.cache.rowsGen != m.rowGen || len(m.cache.rows) >= min(m.size, m.rowCount())+rowCacheMargin {
+		m.cache.rows, m.cache.rowsGen = map[rowKey]string{}, m.rowGen
+	}
+	row, ok := m.cache.rows[key]
+	if !ok {
+		row = render()
+		m.cache.rows[key] = row
+	}
+	return row
+}
@@ -0,0 +1,192 @@
+package options
+
+import "strings"
+
+// SelectionMode describes how options are chosen.
+type SelectionMode int
+
+// Available selection modes.
+const (
+	// SelectOne chooses a single option with the Select key. No marks are
+	// shown.
+	SelectOne SelectionMode = iota
+
+	// SelectMany shows a checkbox before each option. The Toggle key checks
+	// and unchecks the option on the cursor and the Select key confirms the
+	// choice.
+	SelectMany
+
+	// SelectRadio shows a radio button before each option. The Toggle and
+	// Select keys check the option on the cursor and uncheck the others.
+	SelectRadio
+)
+
+// String returns a human-readable name of the selection mode.
+func (s SelectionMode) String() string {
+	return [...]string{
+		"one",
+		"many",
+		"radio",
+	}[s]
+}
+
+// Glyphs holds the marks shown before the options in the SelectMany and
+// SelectRadio modes. The marks of a pair may differ in width, in which case
+// the narrower one is padded so that the options line up.
+type Glyphs struct {
+	Unchecked string
+	Checked   string
+	RadioOff  string
+	RadioOn   string
+}
+
+// DefaultGlyphs returns the default, ASCII only, marks.
+func DefaultGlyphs() Glyphs {
+	return Glyphs{
+		Unchecked: "[ ]",
+		Checked:   "[x]",
+		RadioOff:  "( )",
+		RadioOn:   "(*)",
+	}
+}
+
+// UnicodeGlyphs returns rounded marks drawn with Unicode symbols.
+func UnicodeGlyphs() Glyphs {
+	return Glyphs{
+		Unchecked: "☐",
+		Checked:   "☑",
+		RadioOff:  "○",
+		RadioOn:   "●",
+	}
+}
+
+// checkable returns whether the option at index i of Options can be checked.
+// Parents in a tree and group headers can't, nor options yet to arrive from
+// a Provider.
+func (m Model) checkable(i int) bool {
+	return i >= 0 && i < len(m.Options) && !m.pending(i) && m.item(i).Kind == Selectable && !m.branch(i)
+}
+
+// Checked returns whether the option at index i of Options is checked.
+func (m Model) Checked(i int) bool {
+	return m.checked.has(i)
+}
+
+// SetChecked checks or unchecks the option at index i of Options. In the
+// SelectRadio mode checking an option unchecks all the others.
+func (m *Model) SetChecked(i int, v bool) {
+	m.Invalidate()
+	if !m.checkable(i) {
+		return
+	}
+	if v && m.SelectionMode == SelectRadio {
+		m.checked = nil
+	}
+	m.checked = m.checked.with(i, v)
+}
+
+// checkBlock is the number of options whose checked state is copied
+// together when one of them changes.
+const checkBlock = 1024
+
+// checkSet is the checked state of the options, in blocks of checkBlock.
+// It's shared between copies of the model, so it's never modified in place:
+// with copies the block that changes rather than the state of every option,
+// which adds up on long lists toggled one option at a time. A nil block has
+// none of its options checked.
+type checkSet [][]bool
+
+// has returns whether the option at index i is checked.
+func (s checkSet) has(i int) bool {
+	b := i / checkBlock
+	return i >= 0 && b < len(s) && s[b] != nil && s[b][i%checkBlock]
+}
+
+// with returns s with the option at index i checked or unchecked.
+func (s checkSet) with(i int, v bool) checkSet {
+	b := i / checkBlock
+	if s.has(i) == v {
+		return s
+	}
+	t := make(checkSet, max(len(s), b+1))
+	copy(t, s)
+	block := make([]bool, checkBlock)
+	copy(block, t[b])
+	block[i%checkBlock] = v
+	t[b] = block
+	return t
+}
+
+// CheckedIndexes returns the indexes in Options of the checked options, in
+// order.
+func (m Model) CheckedIndexes() []int {
+	var indexes []int
+	for i := range m.Options {
+		if m.Checked(i) {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// CheckedOptions returns the checked options, in order.
+func (m Model) CheckedOptions() []string {
+	var options []string
+	for _, i := range m.CheckedIndexes() {
+		options = append(options, m.Options[i])
+	}
+	return options
+}
+
+// checkedCount returns the number of checked options.
+func (m Model) checkedCount() int {
+	return len(m.CheckedIndexes())
+}
+
+// toggleChecked checks or unchecks the option on the cursor. In the
+// SelectRadio mode it is always checked.
+func (m *Model) toggleChecked() {
+	i := m.optionIndex(m.cursorIndex())
+	m.SetChecked(i, m.SelectionMode == SelectRadio || !m.Checked(i))
+}
+
+// glyphs returns the marks for unchecked and checked options in the current
+// selection mode, which are empty in the SelectOne mode.
+func (m Model) glyphs() (string, string) {
+	g := m.plainGlyphs(m.Glyphs)
+	switch m.SelectionMode {
+	case SelectMany:
+		return g.Unchecked, g.Checked
+	case SelectRadio:
+		return g.RadioOff, g.RadioOn
+	}
+	return "", ""
+}
+
+// checkView renders the mark shown before row r, padded to the wider of the
+// two marks and followed by a space. Rows that can't be checked get a blank
+// column instead. It is empty in the SelectOne mode.
+func (m Model) checkView(r, cursor int) string {
+	off, on := m.glyphs()
+	width := max(stringWidth(off), stringWidth(on))
+	if width == 0 {
+		return ""
+	}
+
+	i := m.optionIndex(r)
+	glyph := ""
+	if m.checkable(i) {
+		glyph = off
+		if m.Checked(i) {
+			glyph = on
+		}
+	}
+	glyph += strings.Repeat(" ", width-stringWidth(glyph)+1)
+	return m.rowStyle(r, cursor).Render(glyph)
+}
+
+// decoration renders the number and mark shown between the cursor and the
+// tree prefix of row r.
+func (m Model) decoration(r, cursor int) string {
+	return m.numberView(r) + m.checkView(r, cursor)
+}
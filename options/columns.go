@@ -0,0 +1,125 @@
+package options
+
+import "strings"
+
+// columnGap is the number of spaces between two columns.
+const columnGap = 2
+
+// Column is a column of tabular options, shown when Model.TableColumns is
+// set.
+type Column struct {
+	// Title is shown in the header row above the options.
+	Title string
+
+	// Width is the number of cells the column takes up. Columns without a
+	// width share the room the others leave in proportion to their Weight,
+	// one by default, or fit their widest cell when the rows are unbounded.
+	Width  int
+	Weight int
+}
+
+// table returns whether the options are laid out in columns.
+func (m Model) table() bool {
+	return len(m.TableColumns) > 0 && m.Layout == LayoutVertical
+}
+
+// columnWidths returns the width of each column, given the width of the
+// rows.
+func (m Model) columnWidths(width int) []int {
+	widths := make([]int, len(m.TableColumns))
+	room, weights := width-m.columnsLead()-(len(m.TableColumns)-1)*columnGap, 0
+	for j, c := range m.TableColumns {
+		if c.Width > 0 {
+			widths[j] = c.Width
+			room -= c.Width
+			continue
+		}
+		if width <= 0 {
+			widths[j] = m.widestCell(j)
+		}
+		weights += max(c.Weight, 1)
+	}
+	if width <= 0 || weights == 0 {
+		return widths
+	}
+
+	room = max(room, 0)
+	last, shared := -1, 0
+	for j, c := range m.TableColumns {
+		if c.Width <= 0 {
+			widths[j] = room * max(c.Weight, 1) / weights
+			shared += widths[j]
+			last = j
+		}
+	}
+	// Give what rounding down left over to the last flexible column.
+	widths[last] += room - shared
+	return widths
+}
+
+// widestCell returns the width of the widest cell of column j, its title
+// included.
+func (m Model) widestCell(j int) int {
+	w := stringWidth(m.TableColumns[j].Title)
+	for i := range m.Options {
+		if cells := m.cells(i); j < len(cells) {
+			w = max(w, stringWidth(singleLine(cells[j])))
+		}
+	}
+	return w
+}
+
+// columnsLead returns the width of everything shown before the first column.
+func (m Model) columnsLead() int {
+	if m.rowCount() == 0 {
+		return m.gutterWidth()
+	}
+	return m.leadWidth(0, "")
+}
+
+// cells returns the cells of the option at index i of Options. Options
+// without cells show their label in the first column.
+func (m Model) cells(i int) []string {
+	if cells := m.item(i).Cells; len(cells) > 0 {
+		return cells
+	}
+	return []string{m.Options[i]}
+}
+
+// cellsText lays out cells in the columns, each cut to the width of its
+// column. It also returns how many runes of the first cell were kept, for
+// the filter matches in it to be highlighted.
+func (m Model) cellsText(cells []string) (string, int) {
+	widths := m.columnWidths(m.listWidth())
+	var (
+		s    strings.Builder
+		kept int
+	)
+	for j, w := range widths {
+		if j > 0 {
+			s.WriteString(strings.Repeat(" ", columnGap))
+		}
+		var cell string
+		if j < len(cells) {
+			cell = singleLine(cells[j])
+		}
+		cell, n := truncate(cell, w, m.Ellipsis)
+		if j == 0 {
+			kept = n
+		}
+		s.WriteString(pad(cell, w))
+	}
+	// Don't pad the row past its last cell.
+	return strings.TrimRight(s.String(), " "), kept
+}
+
+// columnHeaderView renders the titles of the columns, lined up with the
+// cells below them.
+func (m Model) columnHeaderView() string {
+	titles := make([]string, len(m.TableColumns))
+	for j, c := range m.TableColumns {
+		titles[j] = c.Title
+	}
+	text, _ := m.cellsText(titles)
+	return m.Styles.ColumnHeader.Render(strings.Repeat(" ", m.columnsLead()) + text)
+}
@@ -0,0 +1,12 @@
+package options
+
+import "fmt"
+
+// Debug returns the state of the picker's window and modes on a single line
+// without escape sequences, to be logged or pasted in a bug report as it is.
+func (m Model) Debug() string {
+	return fmt.Sprintf("options.Model{id=%d options=%d rows=%d selected=%d index=%d min=%d max=%d height=%d width=%d "+
+		"focused=%t filter=%q query=%q mode=%s checked=%d depth=%d loading=%t}",
+		m.id, len(m.Options), m.rowCount(), m.selected, m.Index(), m.min, m.windowMax(), m.Height, m.Width,
+		!m.blurred, m.filterState, m.FilterInput.Value(), m.SelectionMode, len(m.CheckedIndexes()), m.Depth(), m.loading)
+}
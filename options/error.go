@@ -0,0 +1,34 @@
+package options
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// NewErrorMsg returns a message that shows err in a banner above the
+// options of the pickers it's sent to, until the DismissError key is pressed
+// or the next key acted on.
+func NewErrorMsg(err error) tea.Msg {
+	return errorMsg{err: err}
+}
+
+// Err returns the error shown in the banner, if any.
+func (m Model) Err() error {
+	return m.err
+}
+
+// handleError shows the error msg carries, if it's for the picker. Messages
+// made with NewErrorMsg are for every picker.
+func (m *Model) handleError(msg errorMsg) {
+	if msg.id != 0 && msg.id != m.id {
+		return
+	}
+	m.err = msg.err
+}
+
+// errorView renders the banner showing the error, cut to the width of the
+// rows.
+func (m Model) errorView() string {
+	text := singleLine(m.err.Error())
+	if width := m.rowWidth(); width > 0 {
+		text, _ = truncate(text, max(width-m.Styles.Error.GetHorizontalFrameSize(), 1), m.Ellipsis)
+	}
+	return m.Styles.Error.Render(text)
+}
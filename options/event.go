@@ -0,0 +1,89 @@
+package options
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// EventKind describes what happened in an Event.
+type EventKind int
+
+// Available event kinds.
+const (
+	// EventSelect is sent when an option is selected.
+	EventSelect EventKind = iota
+
+	// EventHighlight is sent when the cursor moves to another option.
+	EventHighlight
+
+	// EventCancel is sent when the Back key is pressed in the root menu,
+	// where there is no submenu to leave.
+	EventCancel
+
+	// EventLoaded is sent when the options are done loading, or have failed
+	// to load with Err.
+	EventLoaded
+)
+
+// String returns a human-readable name of the event kind.
+func (k EventKind) String() string {
+	return [...]string{
+		"select",
+		"highlight",
+		"cancel",
+		"loaded",
+	}[k]
+}
+
+// Event is something that happened in the picker with ID, reported to
+// Model.OnEvent. Index and Option are the index in Options and the label of
+// the option selected or highlighted, or -1 and "" for other events.
+type Event struct {
+	Kind   EventKind
+	ID     int
+	Index  int
+	Option string
+	Err    error
+}
+
+// updateWithEvents updates the model with msg, reporting the events it
+// causes to OnEvent.
+func (m *Model) updateWithEvents(msg tea.Msg) tea.Cmd {
+	selected, i := m.didSelectIndex(msg)
+	var label string
+	if selected {
+		label = m.Options[i]
+	}
+	cancel := m.cancels(msg)
+	highlighted := m.Index()
+	loading := m.loading || m.streaming
+
+	cmd := m.update(msg)
+
+	if selected {
+		m.OnEvent(Event{Kind: EventSelect, ID: m.id, Index: i, Option: label})
+	}
+	if cancel {
+		m.OnEvent(Event{Kind: EventCancel, ID: m.id, Index: -1})
+	}
+	if j := m.Index(); j != highlighted && j != -1 {
+		m.OnEvent(Event{Kind: EventHighlight, ID: m.id, Index: j, Option: m.Options[j]})
+	}
+	if loading && !m.loading && !m.streaming {
+		m.OnEvent(Event{Kind: EventLoaded, ID: m.id, Index: -1, Err: m.loadErr})
+	}
+	return cmd
+}
+
+// cancels returns whether msg is the Back key pressed in the root menu,
+// that the keys matched before it in handleBrowsing leave alone.
+func (m Model) cancels(msg tea.Msg) bool {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || m.keyTarget(keyMsg) != toList || m.Depth() > 0 || !key.Matches(keyMsg, m.KeyMap.Back) {
+		return false
+	}
+	if m.err != nil && key.Matches(keyMsg, m.KeyMap.DismissError) {
+		return false
+	}
+	return m.filterState != FilterApplied || !key.Matches(keyMsg, m.KeyMap.ClearFilter)
+}
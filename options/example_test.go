@@ -0,0 +1,60 @@
+package options_test
+
+import (
+	"fmt"
+
+	"github.com/RemiG26/bubbles/options"
+	"github.com/RemiG26/bubbles/options/optionstest"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func Example() {
+	m := optionstest.New(options.WithOptions([]string{"Apples", "Pears", "Plums"}))
+	optionstest.Send(&m, tea.WindowSizeMsg{Width: 20, Height: 10})
+	run := optionstest.Type(&m, "down", "enter")
+	fmt.Println(run.Selected)
+	// Output: [Pears]
+}
+
+func ExampleModel_SetFilterText() {
+	m := optionstest.New(options.WithOptions([]string{"Apples", "Pears", "Plums"}))
+	optionstest.Send(&m, tea.WindowSizeMsg{Width: 30, Height: 10})
+	m.SetFilterText("pl")
+	fmt.Println(m.FilteredOptions())
+	fmt.Println(optionstest.StripANSI(m.View()))
+	// Output:
+	// [Plums Apples]
+	// [fuzzy] Filter: pl  2/3
+	// > Plums
+	//   Apples
+}
+
+func ExampleModel_ExportSelections() {
+	m := optionstest.New(options.WithOptions([]string{"notes.txt", "my file.txt", "it's.txt"}))
+	m.SelectionMode = options.SelectMany
+	optionstest.Send(&m, tea.WindowSizeMsg{Width: 30, Height: 10})
+	optionstest.Type(&m, " ", "down", " ", "down", " ", "enter")
+	out, err := m.ExportSelections(options.ExportShell)
+	fmt.Println(out, err)
+	// Output: notes.txt 'my file.txt' 'it'\''s.txt' <nil>
+}
+
+func ExampleNewBuilder() {
+	items, err := options.NewBuilder().
+		Group("Fruit").
+		Option("Apples", options.WithDescription("Crisp")).
+		Option("Pears").
+		Option("Other", options.WithDisabled()).
+		Build()
+	if err != nil {
+		panic(err)
+	}
+	m := optionstest.New(options.WithItems(items))
+	optionstest.Send(&m, tea.WindowSizeMsg{Width: 20, Height: 10})
+	fmt.Println(optionstest.StripANSI(m.View()))
+	// Output:
+	// Fruit
+	// > Apples
+	//   Pears
+	//   Other
+}
@@ -0,0 +1,87 @@
+package options
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ExportFormat describes how ExportSelections writes the values selected.
+type ExportFormat int
+
+// Available export formats.
+const (
+	// ExportShell quotes each value for a POSIX shell, where needed, and
+	// separates them with spaces, for the result to be pasted or eval'd as
+	// the arguments of a command.
+	ExportShell ExportFormat = iota
+
+	// ExportLines writes a value per line. Values holding line breaks
+	// can't be told apart from the lines around them, so they fail.
+	ExportLines
+
+	// ExportJSON writes a JSON array of strings.
+	ExportJSON
+)
+
+// String returns a human-readable name of the export format.
+func (f ExportFormat) String() string {
+	return [...]string{
+		"shell",
+		"lines",
+		"json",
+	}[f]
+}
+
+// ErrNotSubmitted is returned by ExportSelections when the picker wasn't
+// left by selecting an option.
+var ErrNotSubmitted = errors.New("options: no selection was made")
+
+// ExportSelections writes the values of the options last selected in
+// format: the values of the options checked at the time in the SelectMany
+// mode, in the order of Options, and the value of the option selected
+// otherwise. It fails with ErrNotSubmitted if the picker wasn't left by
+// selecting an option.
+func (m Model) ExportSelections(format ExportFormat) (string, error) {
+	sel, ok := m.Result()
+	if !ok {
+		return "", ErrNotSubmitted
+	}
+	values := []string{sel.Value}
+	if m.SelectionMode == SelectMany {
+		values = sel.CheckedValues
+	}
+
+	switch format {
+	case ExportShell:
+		quoted := make([]string, len(values))
+		for i, v := range values {
+			quoted[i] = shellQuote(v)
+		}
+		return strings.Join(quoted, " "), nil
+	case ExportLines:
+		for _, v := range values {
+			if strings.ContainsAny(v, "\r\n") {
+				return "", fmt.Errorf("options: %q can't be exported as a line", v)
+			}
+		}
+		return strings.Join(values, "\n"), nil
+	case ExportJSON:
+		b, err := json.Marshal(values)
+		return string(b), err
+	}
+	return "", fmt.Errorf("options: unknown export format %d", format)
+}
+
+// shellQuote quotes s for a POSIX shell to read it back as a single word.
+// Words made only of characters the shell takes literally are left as they
+// are.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_@%+=:,./-") == "" {
+		return s
+	}
+	// Nothing is special between single quotes but the quote itself, which
+	// is closed, escaped and opened again.
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
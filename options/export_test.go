@@ -0,0 +1,136 @@
+package options
+
+import (
+	"encoding/json"
+	"errors"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// exportValues are values the shell would split, expand or stop reading at,
+// were they not quoted.
+var exportValues = []string{
+	"plain", "two words", "it's", `"double"`, "back\\slash", "line\nbreak", "crlf\r\n",
+	"$HOME", "`id`", "*", "a;b", "~", " ", "日本", "-n", "'", "''",
+}
+
+// submitted returns a picker of values, in the SelectMany mode, left by
+// selecting with all of them checked.
+func submitted(t *testing.T, values []string) Model {
+	t.Helper()
+	items := make([]Option, len(values))
+	for i, v := range values {
+		items[i] = Option{Label: "option " + strings.Repeat("i", i), Value: v}
+	}
+	m := newTestModel(WithItems(items))
+	m.SelectionMode = SelectMany
+	for i := range values {
+		m.SetChecked(i, true)
+	}
+	press(&m, "enter")
+	if _, ok := m.Result(); !ok {
+		t.Fatal("enter doesn't select")
+	}
+	return m
+}
+
+func TestExportShellRoundTrip(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("no shell to read the words back")
+	}
+	for _, v := range exportValues {
+		if q := shellQuote(v); q == v && strings.Trim(v, "abcdefghijklmnopqrstuvwxyz-") != "" {
+			t.Errorf("%q isn't quoted", v)
+		}
+	}
+	if q := shellQuote(""); q != "''" {
+		t.Errorf("empty word quoted as %s", q)
+	}
+	out, err := submitted(t, exportValues).ExportSelections(ExportShell)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The shell prints each of the words it reads back after a NUL.
+	cmd := exec.Command(sh, "-c", `for a in `+out+`; do printf '%s\0' "$a"; done`)
+	b, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("%v: %s", err, out)
+	}
+	got := strings.Split(strings.TrimSuffix(string(b), "\x00"), "\x00")
+	if !slices.Equal(got, exportValues) {
+		t.Errorf("read back:\n%q\nwant:\n%q\nfrom:\n%s", got, exportValues, out)
+	}
+}
+
+func TestExportFormats(t *testing.T) {
+	m := submitted(t, []string{"b", "a c", `"q"`})
+	tests := []struct {
+		format ExportFormat
+		want   string
+	}{
+		{ExportShell, `b 'a c' '"q"'`},
+		{ExportLines, "b\na c\n\"q\""},
+		{ExportJSON, `["b","a c","\"q\""]`},
+	}
+	for _, tt := range tests {
+		got, err := m.ExportSelections(tt.format)
+		if err != nil || got != tt.want {
+			t.Errorf("%s: %q, %v, want %q", tt.format, got, err, tt.want)
+		}
+	}
+
+	// The values are in the order of Options, whatever the order checked.
+	m.SetChecked(0, false)
+	m.SetChecked(0, true)
+	press(&m, "enter")
+	if got, _ := m.ExportSelections(ExportLines); got != "b\na c\n\"q\"" {
+		t.Errorf("checked again: %q", got)
+	}
+}
+
+func TestExportJSONRoundTrip(t *testing.T) {
+	out, err := submitted(t, exportValues).ExportSelections(ExportJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	if err := json.Unmarshal([]byte(out), &got); err != nil || !slices.Equal(got, exportValues) {
+		t.Errorf("read back %q, %v", got, err)
+	}
+}
+
+func TestExportErrors(t *testing.T) {
+	if _, err := newTestModel(WithOptions(numbered(2))).ExportSelections(ExportShell); !errors.Is(err, ErrNotSubmitted) {
+		t.Errorf("not submitted: %v", err)
+	}
+	if _, err := submitted(t, []string{"line\nbreak"}).ExportSelections(ExportLines); err == nil {
+		t.Error("value with a line break exported as a line")
+	}
+	if _, err := submitted(t, []string{"a"}).ExportSelections(ExportFormat(9)); err == nil {
+		t.Error("unknown format exported")
+	}
+	m := newTestModel(WithItems([]Option{{Label: "one", Value: "it's"}}))
+	press(&m, "enter")
+	if got, err := m.ExportSelections(ExportShell); err != nil || got != `'it'\''s'` {
+		t.Errorf("single selection: %q, %v", got, err)
+	}
+}
+
+func TestExportAfterSetOptions(t *testing.T) {
+	m := submitted(t, []string{"a", "b", "c"})
+	m.SetOptions([]string{"x"})
+	for _, format := range []ExportFormat{ExportShell, ExportLines, ExportJSON} {
+		var (
+			got string
+			err error
+		)
+		within(t, func() { got, err = m.ExportSelections(format) })
+		want := map[ExportFormat]string{ExportShell: "a b c", ExportLines: "a\nb\nc", ExportJSON: `["a","b","c"]`}[format]
+		if err != nil || got != want {
+			t.Errorf("%v after setting other options: %q, %v, want %q", format, got, err, want)
+		}
+	}
+}
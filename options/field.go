@@ -0,0 +1,120 @@
+package options
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Field wraps a picker for it to be used as a field of a form, labelled with
+// the picker's Title. The field is done once an option is selected with the
+// Select key or a shortcut, as long as Validate accepts the selection. When
+// it doesn't, its error is shown below the options and the field stays open
+// until another selection is accepted.
+type Field struct {
+	Model Model
+
+	// Validate, when set, checks the selection before the field is done.
+	Validate func(Selection) error
+
+	err   error
+	done  bool
+	value any
+}
+
+// NewField returns a field wrapping m, whose Select key is then what
+// completes the field.
+func NewField(m Model) Field {
+	return Field{Model: m}
+}
+
+// Init initializes the picker of the field.
+func (f Field) Init() tea.Cmd {
+	return f.Model.Init()
+}
+
+// Update updates the picker of the field with msg, completing the field
+// when msg selects an option Validate accepts.
+func (f Field) Update(msg tea.Msg) (Field, tea.Cmd) {
+	selects, _ := f.Model.DidSelectIndex(msg)
+	cmd := f.Model.UpdateInPlace(msg)
+	if !selects {
+		return f, cmd
+	}
+	sel, ok := f.Model.Result()
+	if !ok {
+		return f, cmd
+	}
+	f.err = nil
+	if f.Validate != nil {
+		f.err = f.Validate(sel)
+	}
+	f.done = f.err == nil
+	if f.done {
+		f.value = f.selectionValue(sel)
+	}
+	return f, cmd
+}
+
+// selectionValue returns the value of the field for sel: the values of the
+// options checked in the SelectMany mode, and the value of the option
+// selected otherwise.
+func (f Field) selectionValue(sel Selection) any {
+	if f.Model.SelectionMode != SelectMany {
+		return sel.Value
+	}
+	return sel.CheckedValues
+}
+
+// Focus focuses the picker of the field.
+func (f *Field) Focus() tea.Cmd {
+	return f.Model.Focus()
+}
+
+// Blur blurs the picker of the field.
+func (f *Field) Blur() {
+	f.Model.Blur()
+}
+
+// Error returns the error Validate returned for the last selection, if any.
+func (f Field) Error() error {
+	return f.err
+}
+
+// Done returns whether an option has been selected and accepted by
+// Validate.
+func (f Field) Done() bool {
+	return f.done
+}
+
+// Value returns the value of the option selected, as a string, or the
+// values of the options checked in the SelectMany mode, as a []string. It's
+// nil until the field is done.
+func (f Field) Value() any {
+	return f.value
+}
+
+// Reset clears the selection of the field, as Model.Reset does for its
+// picker, for the field to be filled in again.
+func (f *Field) Reset() {
+	f.Model.Reset()
+	f.err, f.done, f.value = nil, false, nil
+}
+
+// View renders the picker of the field, followed by the error Validate
+// returned, if any.
+func (f Field) View() string {
+	view := f.Model.View()
+	if f.err == nil {
+		return view
+	}
+	line := f.Model.Styles.Error.Render(singleLine(f.err.Error()))
+	if f.Model.Accessible {
+		line = stripEscapes(line)
+	}
+	var s strings.Builder
+	s.WriteString(view)
+	s.WriteRune('\n')
+	s.WriteString(indent(line, f.Model.Indent))
+	return s.String()
+}
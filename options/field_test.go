@@ -0,0 +1,114 @@
+package options
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestFieldValidate(t *testing.T) {
+	m := newTestModel(WithItems([]Option{{Label: "red", Value: "r"}, {Label: "green", Value: "g"}}))
+	resize(&m, 30, 10)
+	f := NewField(m)
+	var validated []string
+	f.Validate = func(sel Selection) error {
+		validated = append(validated, sel.Value)
+		if sel.Value == "r" {
+			return errTest
+		}
+		return nil
+	}
+
+	f, _ = f.Update(keyMsg("down"))
+	if f.Done() || f.Error() != nil || f.Value() != nil || len(validated) != 0 {
+		t.Fatalf("moving: done %t, error %v, value %v, validated %q", f.Done(), f.Error(), f.Value(), validated)
+	}
+	f, _ = f.Update(keyMsg("up"))
+	f, _ = f.Update(keyMsg("enter"))
+	if f.Done() || f.Error() != errTest || f.Value() != nil {
+		t.Fatalf("rejected: done %t, error %v, value %v", f.Done(), f.Error(), f.Value())
+	}
+	if lines := strings.Split(f.View(), "\n"); len(lines) != 3 || strings.TrimSpace(lines[2]) != errTest.Error() {
+		t.Errorf("error isn't shown below the options:\n%s", f.View())
+	}
+
+	// The error stays until another selection is accepted.
+	f, _ = f.Update(keyMsg("down"))
+	if f.Error() != errTest {
+		t.Errorf("error %v after moving", f.Error())
+	}
+	f, _ = f.Update(keyMsg("enter"))
+	if !f.Done() || f.Error() != nil || f.Value() != "g" {
+		t.Fatalf("accepted: done %t, error %v, value %v", f.Done(), f.Error(), f.Value())
+	}
+	if strings.Contains(f.View(), errTest.Error()) {
+		t.Errorf("error still shown:\n%s", f.View())
+	}
+	if !slices.Equal(validated, []string{"r", "g"}) {
+		t.Errorf("validated %q, want each selection once", validated)
+	}
+
+	f.Reset()
+	if f.Done() || f.Error() != nil || f.Value() != nil {
+		t.Errorf("reset: done %t, error %v, value %v", f.Done(), f.Error(), f.Value())
+	}
+}
+
+func TestFieldDone(t *testing.T) {
+	tests := []struct {
+		name  string
+		mode  SelectionMode
+		keys  []string
+		value any
+	}{
+		{"select", SelectOne, []string{"down", "enter"}, "b"},
+		{"shortcut", SelectOne, []string{"c"}, "c"},
+		{"checked", SelectMany, []string{" ", "down", "down", " ", "enter"}, []string{"a", "c"}},
+		{"none checked", SelectMany, []string{"enter"}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(WithItems([]Option{
+				{Label: "alpha", Value: "a"},
+				{Label: "beta", Value: "b"},
+				{Label: "gamma", Value: "c", Shortcut: "c"},
+			}))
+			m.SelectionMode = tt.mode
+			f := NewField(m)
+			for _, k := range tt.keys[:len(tt.keys)-1] {
+				f, _ = f.Update(keyMsg(k))
+				if f.Done() {
+					t.Fatalf("done after %q", k)
+				}
+			}
+			f, _ = f.Update(keyMsg(tt.keys[len(tt.keys)-1]))
+			if !f.Done() {
+				t.Fatal("not done")
+			}
+			switch want := tt.value.(type) {
+			case string:
+				if f.Value() != want {
+					t.Errorf("value %v, want %q", f.Value(), want)
+				}
+			case []string:
+				if got, ok := f.Value().([]string); !ok || !slices.Equal(got, want) {
+					t.Errorf("value %#v, want %q", f.Value(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestFieldBlurred(t *testing.T) {
+	f := NewField(newTestModel(WithOptions(numbered(3))))
+	f.Blur()
+	f, _ = f.Update(keyMsg("enter"))
+	if f.Done() {
+		t.Error("done while blurred")
+	}
+	f.Focus()
+	f, _ = f.Update(keyMsg("enter"))
+	if !f.Done() || f.Value() != "o0" {
+		t.Errorf("focused: done %t, value %v", f.Done(), f.Value())
+	}
+}
@@ -0,0 +1,890 @@
+package options
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sahilm/fuzzy"
+	"golang.org/x/text/unicode/norm"
+)
+
+// FilterState describes the current filtering state on the model.
+type FilterState int
+
+// Possible filter states.
+const (
+	Unfiltered    FilterState = iota // no filter set
+	Filtering                        // user is actively setting a filter
+	FilterApplied                    // a filter is applied and user is not editing filter
+)
+
+// String returns a human-readable string of the current filter state.
+func (f FilterState) String() string {
+	return [...]string{
+		"unfiltered",
+		"filtering",
+		"filter applied",
+	}[f]
+}
+
+// FilterMode selects how the filter matches options.
+type FilterMode int
+
+// Available filter modes.
+const (
+	// FilterModeFuzzy matches options containing the characters of the
+	// filter in order, best matches first.
+	FilterModeFuzzy FilterMode = iota
+
+	// FilterModeSubstring matches options containing the filter, ignoring
+	// case. Matches keep the order of Options.
+	FilterModeSubstring
+
+	// FilterModeRegex matches options against the filter compiled as a
+	// regular expression. Matches keep the order of Options.
+	FilterModeRegex
+
+	// FilterModePrefix matches options starting with the filter, ignoring
+	// case. Matches keep the order of Options.
+	FilterModePrefix
+)
+
+// String returns a human-readable name of the filter mode.
+func (f FilterMode) String() string {
+	return [...]string{
+		"fuzzy",
+		"substring",
+		"regex",
+		"prefix",
+	}[f]
+}
+
+// filterModeCycle is the order in which the CycleFilterMode key switches
+// between filter modes.
+var filterModeCycle = []FilterMode{
+	FilterModeFuzzy,
+	FilterModeSubstring,
+	FilterModePrefix,
+	FilterModeRegex,
+}
+
+// FilterFields selects the fields of the options that the filter searches.
+type FilterFields int
+
+// Fields that can be searched by the filter. They can be combined.
+const (
+	FilterOnLabel FilterFields = 1 << iota
+	FilterOnValue
+	FilterOnDescription
+)
+
+// FilterFunc takes the filter value and the labels of the options to search
+// through, and returns the matching options in the order they should be
+// shown.
+type FilterFunc func(query string, options []string) []Rank
+
+// Rank describes an option matched by a filter.
+type Rank struct {
+	// The index of the option in the slice passed to the filter.
+	Index int
+	// Indexes of the runes in the option that were matched by the filter.
+	MatchedIndexes []int
+}
+
+// DefaultFilter uses sahilm/fuzzy to match options, best matches first.
+// Options equal to the query rank above options starting with it, which rank
+// above all other matches. Ties keep the order of the options.
+func DefaultFilter(query string, options []string) []Rank {
+	type ranked struct {
+		fuzzy.Match
+		tier int
+	}
+
+	q := foldRunes(query)
+	matches := fuzzy.FindNoSort(query, options)
+	ranks := make([]ranked, len(matches))
+	for i, match := range matches {
+		ranks[i] = ranked{Match: match, tier: matchTier(q, foldRunes(match.Str))}
+	}
+	sort.SliceStable(ranks, func(i, j int) bool {
+		if ranks[i].tier != ranks[j].tier {
+			return ranks[i].tier < ranks[j].tier
+		}
+		return ranks[i].Score > ranks[j].Score
+	})
+
+	result := make([]Rank, len(ranks))
+	for i, r := range ranks {
+		result[i] = Rank{
+			Index:          r.Index,
+			MatchedIndexes: runeIndexes(r.Str, r.MatchedIndexes),
+		}
+	}
+	return result
+}
+
+// PrefixFilter matches the options starting with query, ignoring case. The
+// options keep their order.
+func PrefixFilter(query string, options []string) []Rank {
+	q := foldRunes(query)
+	var result []Rank
+	for i, option := range options {
+		if runesHasPrefix(foldRunes(option), q) {
+			result = append(result, Rank{
+				Index:          i,
+				MatchedIndexes: span(0, len(q)),
+			})
+		}
+	}
+	return result
+}
+
+// RegexFilter matches the options against query compiled as a regular
+// expression, highlighting the leftmost match in each. The options keep
+// their order. An invalid expression matches nothing.
+func RegexFilter(query string, options []string) []Rank {
+	re, err := regexp.Compile(query)
+	if err != nil {
+		return nil
+	}
+	return regexFilter(re)(query, options)
+}
+
+// regexFilter returns a filter matching options against re.
+func regexFilter(re *regexp.Regexp) FilterFunc {
+	return func(_ string, options []string) []Rank {
+		var result []Rank
+		for i, option := range options {
+			loc := re.FindStringIndex(option)
+			if loc == nil {
+				continue
+			}
+			start := utf8.RuneCountInString(option[:loc[0]])
+			result = append(result, Rank{
+				Index:          i,
+				MatchedIndexes: span(start, utf8.RuneCountInString(option[loc[0]:loc[1]])),
+			})
+		}
+		return result
+	}
+}
+
+// compileFilter compiles query for the regex filter mode. The last result is
+// kept so that the same query isn't compiled again.
+func (m *Model) compileFilter(query string) (*regexp.Regexp, error) {
+	if m.filterRegex.query != query || (m.filterRegex.re == nil && m.filterRegex.err == nil) {
+		re, err := regexp.Compile(query)
+		m.filterRegex = compiledFilter{query: query, re: re, err: err}
+	}
+	return m.filterRegex.re, m.filterRegex.err
+}
+
+// compiledFilter is a query compiled for the regex filter mode.
+type compiledFilter struct {
+	query string
+	re    *regexp.Regexp
+	err   error
+}
+
+// normalize decomposes s and strips its combining marks, so that accented
+// letters compare equal to their base letters. It also returns, for each rune
+// of the result, the index of the rune of s it comes from.
+func normalize(s string) (string, []int) {
+	var (
+		b         strings.Builder
+		positions []int
+	)
+	for i, r := range []rune(s) {
+		for _, d := range norm.NFD.String(string(r)) {
+			if unicode.Is(unicode.Mn, d) {
+				continue
+			}
+			b.WriteRune(d)
+			positions = append(positions, i)
+		}
+	}
+	return b.String(), positions
+}
+
+// originalIndexes maps rune indexes into a normalized string back to the
+// runes of the original string they come from.
+func originalIndexes(matches, positions []int) []int {
+	var indexes []int
+	for _, j := range matches {
+		if j < 0 || j >= len(positions) {
+			continue
+		}
+		i := positions[j]
+		if n := len(indexes); n > 0 && indexes[n-1] == i {
+			continue
+		}
+		indexes = append(indexes, i)
+	}
+	return indexes
+}
+
+// matchTier ranks how closely option matches query, both already folded:
+// 0 for an exact match, 1 for a prefix match and 2 for anything else.
+func matchTier(query, option []rune) int {
+	switch {
+	case len(query) == len(option) && runesHasPrefix(option, query):
+		return 0
+	case runesHasPrefix(option, query):
+		return 1
+	default:
+		return 2
+	}
+}
+
+// SubstringFilter matches the options containing query, ignoring case. The
+// options keep their order.
+func SubstringFilter(query string, options []string) []Rank {
+	q := foldRunes(query)
+	var result []Rank
+	for i, option := range options {
+		if at := runesIndex(foldRunes(option), q); at != -1 {
+			result = append(result, Rank{
+				Index:          i,
+				MatchedIndexes: span(at, len(q)),
+			})
+		}
+	}
+	return result
+}
+
+// SetFilterMode sets how the filter matches options. An active filter is
+// evaluated again with the new mode.
+func (m *Model) SetFilterMode(mode FilterMode) {
+	m.Invalidate()
+	if m.filterMode == mode {
+		return
+	}
+	m.filterMode = mode
+	if m.filterActive() && m.FilterAsync == nil {
+		m.refilter()
+	}
+}
+
+// FilterMode returns how the filter matches options.
+func (m Model) FilterMode() FilterMode {
+	return m.filterMode
+}
+
+// FilterState returns the current filter state.
+func (m Model) FilterState() FilterState {
+	return m.filterState
+}
+
+// ResetFilter clears the filter and shows all options again.
+func (m *Model) ResetFilter() {
+	m.Invalidate()
+	m.resetFilter()
+}
+
+// SettingFilter returns whether or not the user is currently editing the
+// filter value.
+func (m Model) SettingFilter() bool {
+	return m.filterState == Filtering
+}
+
+// Filtering returns whether the user is typing the filter, for the parent
+// model to hold back its own key bindings meanwhile. It's the same as
+// SettingFilter.
+func (m Model) Filtering() bool {
+	return m.filterState == Filtering
+}
+
+// IsFiltered returns whether or not a filter is applied and the user is no
+// longer editing it.
+func (m Model) IsFiltered() bool {
+	return m.filterState == FilterApplied
+}
+
+// FilterValue returns the current value of the filter.
+func (m Model) FilterValue() string {
+	return m.FilterInput.Value()
+}
+
+// FilterText returns the filter text, as typed by the user or set through
+// SetFilterText. It's the same as FilterValue.
+func (m Model) FilterText() string {
+	return m.FilterInput.Value()
+}
+
+// SetFilteringEnabled enables or disables the Filter key. Disabling it also
+// clears the filter, showing all options again. SetFilterText still filters
+// the options either way.
+func (m *Model) SetFilteringEnabled(v bool) {
+	m.Invalidate()
+	m.KeyMap.Filter.SetEnabled(v)
+	if !v {
+		m.resetFilter()
+	}
+}
+
+// FilteringEnabled returns whether the Filter key is enabled.
+func (m Model) FilteringEnabled() bool {
+	return m.KeyMap.Filter.Enabled()
+}
+
+// FilteredOptions returns the options the filter searches through that
+// match it, in the order they're shown in, or all of those options, in the
+// order of Options, when no filter narrows them. Headers, informational,
+// hidden and branch entries are never among them, nor disabled options
+// unless FilterIncludesDisabled is set. Unlike VisibleOptions, it holds the
+// ones scrolled out of the window too, and ignores collapsed groups.
+func (m Model) FilteredOptions() []string {
+	if !m.filterActive() {
+		targets := m.filterTargets()
+		options := make([]string, len(targets))
+		for j, i := range targets {
+			options[j] = m.Options[i]
+		}
+		return options
+	}
+	options := make([]string, 0, len(m.filtered))
+	for _, f := range m.filtered {
+		// Options may have been shrunk since it was filtered.
+		if f.Index < len(m.Options) {
+			options = append(options, m.Options[f.Index])
+		}
+	}
+	return options
+}
+
+// SetFilterText filters the options as if the user had typed q and applied
+// the filter. It works whether or not the filter key is enabled. If the user
+// is editing the filter, the input is replaced and stays open. An empty q
+// clears the filter. The returned command, if any, is the FilterAsync call.
+func (m *Model) SetFilterText(q string) tea.Cmd {
+	m.Invalidate()
+	if q == "" {
+		m.resetFilter()
+		return nil
+	}
+	m.FilterInput.SetValue(q)
+	if m.filterState == Unfiltered {
+		m.filterState = FilterApplied
+	}
+	return m.runFilter()
+}
+
+// filterActive returns whether the shown rows are narrowed by a filter.
+func (m Model) filterActive() bool {
+	return m.filterState != Unfiltered && m.filterQuery != ""
+}
+
+// filterTargets returns the indexes of the options the filter searches
+// through, which are the ones that could be selected.
+func (m Model) filterTargets() []int {
+	targets := make([]int, 0, len(m.Options))
+	tree := m.tree()
+	for i := range m.Options {
+		if m.pending(i) {
+			continue
+		}
+		if i < len(m.items) {
+			// Look at the entry in place, as copying it, or the model to
+			// call its methods, adds up on large lists.
+			item := &m.items[i]
+			if item.Disabled && !m.FilterIncludesDisabled || item.Hidden || item.Kind != Selectable {
+				continue
+			}
+		}
+		if !tree || !m.nodes[i].branch {
+			targets = append(targets, i)
+		}
+	}
+	return targets
+}
+
+// refilter matches the options against the filter value and moves the
+// cursor to the best match.
+func (m *Model) refilter() {
+	m.filterPending = false
+	m.filterErr = nil
+	filter := m.filterFunc()
+	value := m.FilterInput.Value()
+	query := value
+	if m.FilterNormalize {
+		query, _ = normalize(query)
+	}
+	if m.Filter == nil && m.filterMode == FilterModeRegex && query != "" {
+		re, err := m.compileFilter(query)
+		if err != nil {
+			// Keep showing the results of the last valid expression, which
+			// is matched again if they were dropped with the options they
+			// were of.
+			m.filterErr = err
+			if m.filtered != nil || m.filterQuery == "" {
+				return
+			}
+			value, query = m.filterQuery, m.filterQuery
+			if m.FilterNormalize {
+				query, _ = normalize(query)
+			}
+			if re, err = m.compileFilter(query); err != nil {
+				return
+			}
+		}
+		filter = regexFilter(re)
+	}
+
+	m.filterQuery = value
+	m.filtered = nil
+	if m.filterActive() {
+		targets := m.filterTargets()
+		m.filterTotal = len(targets)
+		fields := m.FilterFields
+		if fields == 0 {
+			fields = FilterOnLabel
+		}
+
+		// Each field is filtered on its own, and options are shown in the
+		// order their first matching field was searched.
+		matched := make(map[int]bool)
+		for _, field := range []FilterFields{FilterOnLabel, FilterOnValue, FilterOnDescription} {
+			if fields&field == 0 {
+				continue
+			}
+			var (
+				ranks     []Rank
+				positions [][]int
+			)
+			if field == FilterOnLabel && m.usesIndex() {
+				ranks = m.searchIndex(query, targets)
+			} else {
+				texts := make([]string, len(targets))
+				if m.FilterNormalize {
+					positions = make([][]int, len(targets))
+				}
+				for i, t := range targets {
+					texts[i] = m.fieldText(t, field)
+					if m.FilterNormalize {
+						texts[i], positions[i] = normalize(texts[i])
+					}
+				}
+				ranks = filter(query, texts)
+			}
+			for _, r := range ranks {
+				if r.Index < 0 || r.Index >= len(targets) || matched[targets[r.Index]] {
+					continue
+				}
+				// Only matches in the label can be highlighted.
+				if field != FilterOnLabel {
+					r.MatchedIndexes = nil
+				} else if positions != nil {
+					r.MatchedIndexes = originalIndexes(r.MatchedIndexes, positions[r.Index])
+				}
+				r.Index = targets[r.Index]
+				matched[r.Index] = true
+				m.filtered = append(m.filtered, r)
+			}
+		}
+		if m.FilterKeepOrder {
+			sort.SliceStable(m.filtered, func(i, j int) bool {
+				return m.filtered[i].Index < m.filtered[j].Index
+			})
+		}
+	}
+	m.resetRows()
+}
+
+// runFilter filters the options against the filter value, or asks
+// FilterAsync to when it is set.
+func (m *Model) runFilter() tea.Cmd {
+	if m.FilterAsync == nil || m.FilterInput.Value() == "" {
+		m.refilter()
+		return nil
+	}
+	m.filterPending = true
+	cmd := m.FilterAsync(m.FilterInput.Value())
+	if cmd == nil {
+		return nil
+	}
+	id := m.id
+	return func() tea.Msg {
+		msg := cmd()
+		if results, ok := msg.(FilterResultsMsg); ok && results.ID == 0 {
+			results.ID = id
+			return results
+		}
+		return msg
+	}
+}
+
+// handleFilterResults shows the results of FilterAsync, unless they are for
+// a filter value that has since changed or been cleared.
+func (m *Model) handleFilterResults(msg FilterResultsMsg) {
+	if msg.ID != m.id || m.filterState == Unfiltered || msg.Query != m.FilterInput.Value() {
+		return
+	}
+	m.filterPending = false
+	m.filterErr = nil
+	m.filterQuery = msg.Query
+
+	targets := m.filterTargets()
+	m.filterTotal = len(targets)
+	byLabel := make(map[string][]int)
+	for _, t := range targets {
+		byLabel[m.Options[t]] = append(byLabel[m.Options[t]], t)
+	}
+	m.filtered = nil
+	for _, option := range msg.Options {
+		indexes := byLabel[option]
+		if len(indexes) == 0 {
+			continue
+		}
+		m.filtered = append(m.filtered, Rank{Index: indexes[0]})
+		byLabel[option] = indexes[1:]
+	}
+	m.resetRows()
+}
+
+// fieldText returns the text of the given field of the option at index i.
+// A value that merely defaults to the label is empty here, so that it isn't
+// matched twice.
+func (m Model) fieldText(i int, field FilterFields) string {
+	switch field {
+	case FilterOnValue:
+		return m.item(i).Value
+	case FilterOnDescription:
+		return m.item(i).Description
+	default:
+		return m.Options[i]
+	}
+}
+
+// filterFunc returns the filter in use, which is Filter when set and
+// otherwise the built-in filter for the filter mode.
+func (m Model) filterFunc() FilterFunc {
+	if m.Filter != nil {
+		return m.Filter
+	}
+	switch m.filterMode {
+	case FilterModeSubstring:
+		return SubstringFilter
+	case FilterModeRegex:
+		return RegexFilter
+	case FilterModePrefix:
+		return PrefixFilter
+	default:
+		return DefaultFilter
+	}
+}
+
+// foldRunes returns the runes of s folded to a single case, so that two
+// strings that are equal under Unicode case folding fold to equal runes.
+func foldRunes(s string) []rune {
+	runes := []rune(s)
+	for i, r := range runes {
+		runes[i] = unicode.ToLower(unicode.ToUpper(r))
+	}
+	return runes
+}
+
+// runesIndex returns the index of the first instance of sub in s, or -1 if
+// sub is not present in s.
+func runesIndex(s, sub []rune) int {
+	for i := 0; i+len(sub) <= len(s); i++ {
+		if runesHasPrefix(s[i:], sub) {
+			return i
+		}
+	}
+	return -1
+}
+
+// runesHasPrefix returns whether s begins with prefix.
+func runesHasPrefix(s, prefix []rune) bool {
+	if len(prefix) > len(s) {
+		return false
+	}
+	for i, r := range prefix {
+		if s[i] != r {
+			return false
+		}
+	}
+	return true
+}
+
+// span returns the n consecutive indexes starting at start.
+func span(start, n int) []int {
+	indexes := make([]int, n)
+	for i := range indexes {
+		indexes[i] = start + i
+	}
+	return indexes
+}
+
+// runeIndexes converts byte offsets into s to rune indexes.
+func runeIndexes(s string, offsets []int) []int {
+	indexes := make([]int, 0, len(offsets))
+	var r, o int
+	for i := range s {
+		for o < len(offsets) && offsets[o] == i {
+			indexes = append(indexes, r)
+			o++
+		}
+		r++
+	}
+	return indexes
+}
+
+// resetFilter clears the filter and shows all options again, keeping the
+// cursor on the option it was on.
+func (m *Model) resetFilter() {
+	if m.filterState == Unfiltered {
+		return
+	}
+	m.filterState = Unfiltered
+	m.FilterInput.Reset()
+	m.FilterInput.Blur()
+	m.filterQuery = ""
+	m.filterPending = false
+	m.filterErr = nil
+	m.filtered = nil
+	m.relayout()
+}
+
+// handleFiltering handles messages while the user is editing the filter.
+func (m *Model) handleFiltering(msg tea.Msg) tea.Cmd {
+	// Pasted text goes into the input as it is, whatever keys it holds.
+	if msg, ok := msg.(tea.KeyMsg); ok && !pasted(msg) {
+		switch {
+		case key.Matches(msg, m.KeyMap.CancelWhileFiltering):
+			m.resetFilter()
+			return nil
+		case key.Matches(msg, m.KeyMap.CycleFilterMode):
+			m.cycleFilterMode()
+			return nil
+		case key.Matches(msg, m.KeyMap.AcceptWhileFiltering):
+			if m.FilterInput.Value() == "" {
+				m.resetFilter()
+				return nil
+			}
+			// Results of FilterAsync are still shown when they arrive.
+			if m.filterPending && m.FilterAsync == nil {
+				m.refilter()
+			}
+			m.filterState = FilterApplied
+			m.FilterInput.Blur()
+			return nil
+		}
+	}
+
+	input, cmd := m.FilterInput.Update(msg)
+	changed := input.Value() != m.FilterInput.Value()
+	m.FilterInput = input
+	if changed {
+		return tea.Batch(cmd, m.scheduleFilter())
+	}
+	return cmd
+}
+
+// pasted returns whether msg holds text pasted in rather than a key press:
+// the terminal hands over several runes at once then.
+func pasted(msg tea.KeyMsg) bool {
+	return msg.Type == tea.KeyRunes && len(msg.Runes) > 1
+}
+
+// liveFilterKey returns whether msg edits the query of a live filter, or
+// cycles its mode, rather than controlling the list.
+func (m Model) liveFilterKey(msg tea.KeyMsg) bool {
+	switch {
+	case pasted(msg):
+		return true
+	case key.Matches(msg, m.KeyMap.CycleFilterMode):
+		return true
+	case msg.Type == tea.KeyRunes, msg.Type == tea.KeySpace:
+		return true
+	case msg.Type == tea.KeyBackspace && m.FilterInput.Value() != "":
+		return true
+	}
+	return false
+}
+
+// handleLiveFilter passes a key press for which liveFilterKey is true on to
+// the filter input of a live filter.
+func (m *Model) handleLiveFilter(msg tea.KeyMsg) tea.Cmd {
+	if key.Matches(msg, m.KeyMap.CycleFilterMode) {
+		m.cycleFilterMode()
+		return nil
+	}
+
+	// Focusing the input in place would hand the model to the command it
+	// returns, moving every model updated to the heap.
+	input := m.FilterInput
+	input.Focus()
+	input, cmd := input.Update(msg)
+	changed := input.Value() != m.FilterInput.Value()
+	m.FilterInput = input
+	switch {
+	case !changed:
+		return cmd
+	case input.Value() == "":
+		m.resetFilter()
+		return cmd
+	}
+	m.filterState = FilterApplied
+	return tea.Batch(cmd, m.scheduleFilter())
+}
+
+// FilterResultsMsg carries the results of a FilterAsync call. ID is the ID of
+// the picker, filled in when left zero, Query the filter value the results
+// are for and Options the labels of the matching options, best matches
+// first. Options that aren't among the picker's Options are ignored.
+type FilterResultsMsg struct {
+	ID      int
+	Query   string
+	Options []string
+}
+
+// filterDebounceMsg is sent when the filter value may have settled.
+type filterDebounceMsg struct {
+	id  int
+	seq int
+}
+
+// scheduleFilter filters the options right away, or once FilterDebounce has
+// passed without the filter value changing again.
+func (m *Model) scheduleFilter() tea.Cmd {
+	if m.FilterDebounce <= 0 {
+		return m.runFilter()
+	}
+	m.filterSeq++
+	m.filterPending = true
+	id, seq := m.id, m.filterSeq
+	return tea.Tick(m.FilterDebounce, func(time.Time) tea.Msg {
+		return filterDebounceMsg{id: id, seq: seq}
+	})
+}
+
+// handleFilterDebounce runs a pending filter pass if no further changes to
+// the filter value have been made since msg was scheduled.
+func (m *Model) handleFilterDebounce(msg filterDebounceMsg) tea.Cmd {
+	if msg.id != m.id || msg.seq != m.filterSeq || !m.filterPending {
+		return nil
+	}
+	return m.runFilter()
+}
+
+// cycleFilterMode switches to the next filter mode. Filter modes don't apply
+// to FilterAsync.
+func (m *Model) cycleFilterMode() {
+	if m.FilterAsync != nil {
+		return
+	}
+	next := filterModeCycle[0]
+	for i, mode := range filterModeCycle {
+		if mode == m.filterMode && i+1 < len(filterModeCycle) {
+			next = filterModeCycle[i+1]
+		}
+	}
+	m.SetFilterMode(next)
+	// Pick up a query that a failed regex left unapplied.
+	if m.FilterInput.Value() != m.filterQuery {
+		m.refilter()
+	}
+}
+
+// startFiltering puts the model into the filter editing state.
+func (m *Model) startFiltering() tea.Cmd {
+	m.filterState = Filtering
+	input := m.FilterInput
+	input.CursorEnd()
+	input.Focus()
+	m.FilterInput = input
+	return textinput.Blink
+}
+
+// rowMatches returns the rune indexes matched by the filter in the label of
+// the option on row r.
+func (m Model) rowMatches(r int) []int {
+	if !m.filterActive() || r < 0 || r >= len(m.filtered) {
+		return nil
+	}
+	return m.filtered[r].MatchedIndexes
+}
+
+// highlight renders label with style, applying Styles.FilterMatch on top of
+// it for the runes at the given indexes.
+func (m Model) highlight(label string, matches []int, style lipgloss.Style) string {
+	if len(matches) == 0 {
+		return style.Render(label)
+	}
+	return lipgloss.StyleRunes(label, matches, m.Styles.FilterMatch.Copy().Inherit(style), style)
+}
+
+// highlightDisabled renders label like highlight, except that the disabled
+// style takes precedence over Styles.FilterMatch for the matched runes.
+func (m Model) highlightDisabled(label string, matches []int, style lipgloss.Style) string {
+	if len(matches) == 0 {
+		return style.Render(label)
+	}
+	return lipgloss.StyleRunes(label, matches, style.Copy().Inherit(m.Styles.FilterMatch), style)
+}
+
+// noMatchesView renders the message shown when the filter matches nothing.
+func (m Model) noMatchesView() string {
+	text := fmt.Sprintf("Nothing matches '%s' — %s to clear",
+		singleLine(m.filterQuery), m.KeyMap.ClearFilter.Help().Key)
+	return m.fitLine(m.Styles.NoMatches.Render(text), m.rowWidth())
+}
+
+// fitLine cuts the rendered line s to width cells, unless width is zero, so
+// that it isn't wrapped onto more lines than it's counted for.
+func (m Model) fitLine(s string, width int) string {
+	if width > 0 {
+		s, _ = truncate(s, width, m.Ellipsis)
+	}
+	return s
+}
+
+// filterView renders the filter input line, followed by the line showing
+// the error of an invalid regular expression, if any. Both are cut to the
+// width of the picker.
+func (m Model) filterView() string {
+	input := m.FilterInput
+	input.PromptStyle = m.Styles.FilterPrompt
+	input.Cursor.Style = m.Styles.FilterCursor
+	if m.LiveFilter {
+		input.Focus()
+	}
+	view := input.View()
+	if m.Filter == nil && m.FilterAsync == nil {
+		view = m.Styles.FilterMode.Render("["+m.filterMode.String()+"] ") + view
+	}
+	if m.filterActive() {
+		view += m.Styles.FilterCount.Render(fmt.Sprintf(" %d/%d", len(m.filtered), m.filterTotal))
+	}
+	switch {
+	case m.filterPending && m.FilterAsync != nil:
+		view += m.Styles.FilterPending.Render(" searching…")
+	case m.filterPending:
+		view += m.Styles.FilterPending.Render(" filtering…")
+	}
+	view = m.fitLine(view, m.contentWidth())
+	if m.filterErr != nil {
+		view += "\n" + m.fitLine(m.Styles.FilterError.Render(singleLine(m.filterErr.Error())), m.contentWidth())
+	}
+	return view
+}
+
+func newFilterInput(r *lipgloss.Renderer) textinput.Model {
+	input := textinput.New()
+	input.Prompt = "Filter: "
+	input.TextStyle = r.NewStyle()
+	input.PlaceholderStyle = r.NewStyle().Foreground(faintColor)
+	input.CompletionStyle = r.NewStyle().Foreground(faintColor)
+	input.Cursor.TextStyle = r.NewStyle()
+	return input
+}
@@ -0,0 +1,142 @@
+package options
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSetFilterText(t *testing.T) {
+	options := []string{"main", "feature/Login", "feature/logout", "fix/typo", "release"}
+	tests := []struct {
+		name     string
+		keys     []string
+		disabled bool
+		query    string
+		state    FilterState
+		want     []string
+	}{
+		{name: "applied", query: "log", state: FilterApplied, want: []string{"feature/Login", "feature/logout"}},
+		{name: "case", query: "LOGIN", state: FilterApplied, want: []string{"feature/Login"}},
+		{name: "while typing", keys: []string{"/", "x"}, query: "fix", state: Filtering, want: []string{"fix/typo"}},
+		{name: "replacing applied", keys: []string{"/", "m", "enter"}, query: "rele", state: FilterApplied, want: []string{"release"}},
+		{name: "empty", keys: []string{"/", "m", "enter"}, query: "", state: Unfiltered, want: options},
+		{name: "filter key disabled", disabled: true, query: "typo", state: FilterApplied, want: []string{"fix/typo"}},
+		{name: "no matches", query: "zzz", state: FilterApplied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(WithOptions(options))
+			m.KeyMap.Filter.SetEnabled(!tt.disabled)
+			resize(&m, 30, 10)
+			press(&m, tt.keys...)
+			m.SetFilterText(tt.query)
+			if m.FilterState() != tt.state || m.Filtering() != (tt.state == Filtering) || m.FilterValue() != tt.query {
+				t.Errorf("state %v, filtering %t, value %q", m.FilterState(), m.Filtering(), m.FilterValue())
+			}
+			if got := m.VisibleOptions(); !slices.Equal(got, tt.want) {
+				t.Errorf("VisibleOptions() = %q, want %q", got, tt.want)
+			}
+			if tt.want == nil {
+				if view := m.View(); !strings.Contains(view, "Nothing matches 'zzz'") {
+					t.Errorf("no matches in:\n%s", view)
+				}
+				return
+			}
+			if shown := cursorLabel(t, m); shown != tt.want[0] {
+				t.Errorf("cursor on %q, want the best match %q", shown, tt.want[0])
+			}
+			if ok, option := m.DidSelectOption(keyMsg("enter")); tt.state != Filtering && (!ok || option != tt.want[0]) {
+				t.Errorf("DidSelectOption = %t, %q, want %q", ok, option, tt.want[0])
+			}
+		})
+	}
+}
+
+func TestSetFilterTextShrinks(t *testing.T) {
+	m := newTestModel(WithOptions(numbered(50)))
+	resize(&m, 20, 10)
+	press(&m, times(45, "down")...)
+	for _, q := range []string{"4", "44", "o44", "o", ""} {
+		m.SetFilterText(q)
+		if r := m.cursorIndex(); r == -1 || !m.inWindow(r) {
+			t.Errorf("filter %q: cursor on %d outside the window at %d", q, r, m.min)
+		}
+		if i := m.Index(); i < 0 || !strings.Contains(m.Options[i], q) {
+			t.Errorf("filter %q: cursor on %d", q, i)
+		}
+		press(&m, "down", "up")
+	}
+}
+
+func TestFilteredOptionsSameSet(t *testing.T) {
+	m := newTestModel(WithItems([]Option{
+		{Label: "a header", Kind: Header},
+		{Label: "a note", Kind: Info},
+		{Label: "a secret", Hidden: true},
+		{Label: "a gone", Disabled: true},
+		{Label: "apple"},
+		{Label: "apricot"},
+	}))
+	want := []string{"apple", "apricot"}
+	if got := m.FilteredOptions(); !slices.Equal(got, want) {
+		t.Errorf("FilteredOptions() = %q unfiltered, want %q", got, want)
+	}
+	m.SetFilterText("a")
+	got := m.FilteredOptions()
+	slices.Sort(got)
+	if !slices.Equal(got, want) {
+		t.Errorf("FilteredOptions() = %q filtered by a query matching all of them, want %q", got, want)
+	}
+}
+
+func TestSetOptionsUnderInvalidRegex(t *testing.T) {
+	m := newTestModel(WithOptions([]string{"one", "two", "four", "five", "six", "seven"}))
+	resize(&m, 30, 10)
+	m.SetFilterMode(FilterModeRegex)
+	m.SetFilterText("o")
+	m.SetFilterText("o(")
+	if m.filterErr == nil {
+		t.Fatal("no error for an invalid expression")
+	}
+	m.SetOptions([]string{"x", "go", "z"})
+
+	// The last valid expression is matched against the new options.
+	if got := m.VisibleOptions(); !slices.Equal(got, []string{"go"}) || m.rowCount() != 1 {
+		t.Errorf("VisibleOptions() = %q in %d rows, want [go]", got, m.rowCount())
+	}
+	if m.filterErr == nil || m.FilterValue() != "o(" {
+		t.Errorf("filter %q without the error of the expression", m.FilterValue())
+	}
+	if ok, option := m.DidSelectOption(keyMsg("enter")); !ok || option != "go" {
+		t.Errorf("DidSelectOption = %v, %q, want go", ok, option)
+	}
+}
+
+func TestFilteredOptionsAfterOptionsShrink(t *testing.T) {
+	m := newTestModel(WithOptions([]string{"apple", "apricot", "avocado", "banana"}))
+	m.SetFilterText("a")
+	m.Options = m.Options[:2]
+	got := m.FilteredOptions()
+	slices.Sort(got)
+	if want := []string{"apple", "apricot"}; !slices.Equal(got, want) {
+		t.Errorf("FilteredOptions() = %q after shrinking Options, want %q", got, want)
+	}
+}
+
+func TestSetFilterTextLong(t *testing.T) {
+	long := strings.Repeat("a long path segment/", 10) + "file.txt"
+	m := newTestModel(WithOptions([]string{"file.txt", long}))
+	resize(&m, 30, 10)
+	m.SetFilterText(long)
+	if got := m.FilterValue(); got != long {
+		t.Fatalf("FilterValue() = %q, want the %d runes set", got, len(long))
+	}
+	if got := m.FilteredOptions(); !slices.Equal(got, []string{long}) {
+		t.Errorf("FilteredOptions() = %q", got)
+	}
+	press(&m, "/", "!")
+	if got := m.FilterValue(); got != long+"!" {
+		t.Errorf("typing after a long query gives %q", got)
+	}
+}
@@ -0,0 +1,54 @@
+package options
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// Selection is the option selected with the Select key or a shortcut.
+type Selection struct {
+	// Index and Option are the index in Options and the label of the option
+	// selected, and Value its value.
+	Index  int
+	Option string
+	Value  string
+
+	// Checked are the indexes in Options of the options checked at the
+	// time, in the SelectMany and SelectRadio modes, and CheckedValues
+	// their values, which stay the same when the options are set since.
+	Checked       []int
+	CheckedValues []string
+}
+
+// WasSubmitted returns whether the picker was last left by selecting an
+// option, until Reset.
+func (m Model) WasSubmitted() bool {
+	return m.submitted
+}
+
+// WasCancelled returns whether the picker was last left with the Back key
+// in the root menu, until Reset.
+func (m Model) WasCancelled() bool {
+	return m.cancelled
+}
+
+// Result returns the option last selected, if the picker was last left by
+// selecting one.
+func (m Model) Result() (Selection, bool) {
+	return m.result, m.submitted
+}
+
+// finish records how the picker was left: by selecting sel, or cancelled
+// when sel is nil. It returns tea.Quit when QuitOnSelect or QuitOnCancel
+// asks for the program to quit then.
+func (m *Model) finish(sel *Selection) tea.Cmd {
+	if sel == nil {
+		m.result, m.submitted, m.cancelled = Selection{}, false, true
+		if m.QuitOnCancel {
+			return tea.Quit
+		}
+		return nil
+	}
+	m.result, m.submitted, m.cancelled = *sel, true, false
+	if m.QuitOnSelect {
+		return tea.Quit
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package options
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// FocusGroupKeyMap defines the key bindings that move the focus between the
+// pickers of a FocusGroup.
+type FocusGroupKeyMap struct {
+	Next key.Binding
+	Prev key.Binding
+}
+
+// DefaultFocusGroupKeyMap defines the default keybindings of a FocusGroup.
+func DefaultFocusGroupKeyMap() FocusGroupKeyMap {
+	return FocusGroupKeyMap{
+		Next: key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "next pane")),
+		Prev: key.NewBinding(key.WithKeys("shift+tab"), key.WithHelp("shift+tab", "prev pane")),
+	}
+}
+
+// FocusGroup holds pickers shown side by side, of which one at a time is
+// focused. Key presses go to the focused picker and other messages to all
+// of them. The Next and Prev keys move the focus, except while the focused
+// picker's filter is being edited, and take precedence over bindings on the
+// same keys in the pickers, such as ToggleGroup.
+type FocusGroup struct {
+	Pickers []Model
+	KeyMap  FocusGroupKeyMap
+
+	// Gap is the number of spaces between the pickers in the view.
+	Gap int
+
+	focused int
+}
+
+// NewFocusGroup returns a FocusGroup of pickers with the first one focused.
+func NewFocusGroup(pickers ...Model) FocusGroup {
+	g := FocusGroup{
+		Pickers: pickers,
+		KeyMap:  DefaultFocusGroupKeyMap(),
+		Gap:     2,
+	}
+	for i := range g.Pickers {
+		if i == 0 {
+			g.Pickers[i].Focus()
+		} else {
+			g.Pickers[i].Blur()
+		}
+	}
+	return g
+}
+
+// Init initializes the pickers of the group.
+func (g FocusGroup) Init() tea.Cmd {
+	cmds := make([]tea.Cmd, len(g.Pickers))
+	for i, m := range g.Pickers {
+		cmds[i] = m.Init()
+	}
+	return tea.Batch(cmds...)
+}
+
+// Update routes msg to the pickers of the group, moving the focus on the
+// Next and Prev keys.
+func (g FocusGroup) Update(msg tea.Msg) (FocusGroup, tea.Cmd) {
+	if len(g.Pickers) == 0 {
+		return g, nil
+	}
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		if !g.Pickers[g.focused].SettingFilter() {
+			switch {
+			case key.Matches(msg, g.KeyMap.Next):
+				return g, g.Focus(g.focused + 1)
+			case key.Matches(msg, g.KeyMap.Prev):
+				return g, g.Focus(g.focused - 1)
+			}
+		}
+		var cmd tea.Cmd
+		g.Pickers[g.focused], cmd = g.Pickers[g.focused].Update(msg)
+		return g, cmd
+	}
+
+	cmds := make([]tea.Cmd, len(g.Pickers))
+	for i := range g.Pickers {
+		g.Pickers[i], cmds[i] = g.Pickers[i].Update(msg)
+	}
+	return g, tea.Batch(cmds...)
+}
+
+// Focus moves the focus to the picker at index i, wrapping around at either
+// end of the group.
+func (g *FocusGroup) Focus(i int) tea.Cmd {
+	n := len(g.Pickers)
+	if n == 0 {
+		return nil
+	}
+	i = (i%n + n) % n
+	g.Pickers[g.focused].Blur()
+	g.focused = i
+	return g.Pickers[i].Focus()
+}
+
+// Focused returns the index of the focused picker.
+func (g FocusGroup) Focused() int {
+	return g.focused
+}
+
+// View renders the pickers next to each other.
+func (g FocusGroup) View() string {
+	views := make([]string, 0, 2*len(g.Pickers))
+	for i, m := range g.Pickers {
+		if i > 0 && g.Gap > 0 {
+			views = append(views, strings.Repeat(" ", g.Gap))
+		}
+		views = append(views, m.View())
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, views...)
+}
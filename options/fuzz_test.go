@@ -0,0 +1,158 @@
+package options
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// fuzzKeys are the keys FuzzUpdate presses, picked by the bytes it's given.
+var fuzzKeys = []string{
+	"up", "down", "up", "down", "pgup", "pgdown", "home", "end", "left", "right",
+	"enter", "esc", " ", "tab", "backspace", "/", "o", "a", "p", "1",
+}
+
+// fuzzItems returns options made up from data: headers, some of them
+// collapsed, informational, disabled and hidden rows, long labels and
+// parents with children.
+func fuzzItems(data []byte) []Option {
+	var items []Option
+	for i, b := range data {
+		item := Option{Label: "opt " + strconv.Itoa(i)}
+		switch b % 9 {
+		case 0:
+			item.Kind, item.Collapsed = Header, b&0x10 != 0
+		case 1:
+			item.Kind = Info
+		case 2:
+			item.Disabled = true
+		case 3:
+			item.Hidden = true
+		case 4:
+			item.Label += " with a label long enough to be cut or wrapped"
+		case 5:
+			item.Children = []Option{
+				{Label: "child a"},
+				{Label: "child b", Kind: Header, Collapsed: b&0x10 != 0},
+				{Label: "child c"},
+			}
+			item.Expanded = b&0x20 != 0
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// checkInvariants returns an error if the window of m doesn't hold the
+// cursor, or isn't within the list, or if the view is taller than the
+// terminal.
+func checkInvariants(m Model, height int) error {
+	view := m.View()
+	if n := m.rowCount(); n > 0 {
+		if m.selected < 0 || m.selected >= n {
+			return fmt.Errorf("selected %d out of %d rows", m.selected, n)
+		}
+		if m.min < 0 || m.min >= max(m.lineCount(), 1) {
+			return fmt.Errorf("window starts at %d of %d lines", m.min, m.lineCount())
+		}
+		if r := m.cursorIndex(); r != -1 && !m.inWindow(r) {
+			return fmt.Errorf("cursor on row %d outside the window at %d", r, m.min)
+		}
+	}
+	if lines := strings.Count(view, "\n") + 1; height >= minLines(m) && lines > height {
+		return fmt.Errorf("view is %d lines tall in a terminal %d lines high:\n%s", lines, height, view)
+	}
+	return nil
+}
+
+// minLines returns the fewest lines the view of m can take up with a
+// single row: the lines around the options and the ones the window makes
+// room for.
+func minLines(m Model) int {
+	n := m.chromeLines() + 1 + m.DescriptionLines
+	if m.LiveFilter {
+		n++
+	}
+	if m.ShowOverflowHints {
+		n += 2
+	}
+	if m.Paginated {
+		n++
+	}
+	return n
+}
+
+// runSteps builds a picker from items and updates it with a key press or a
+// resize for each of steps, returning an error at the first step leaving it
+// in a state checkInvariants rejects.
+func runSteps(items, steps []byte) error {
+	m := newTestModel(WithItems(fuzzItems(items)))
+	if len(items) > 0 {
+		flags := items[0]
+		m.SelectableHeaders = flags&0x40 != 0
+		m.WrapLongOptions = flags&0x80 != 0
+		m.ShowOverflowHints = len(items) > 1 && items[1]&0x40 != 0
+		m.StickyHeaders = len(items) > 1 && items[1]&0x80 != 0
+		m.Paginated = len(items) > 1 && items[1]&0x20 != 0
+		m.ShowStatusBar = len(items) > 1 && items[1]&0x10 != 0
+		if len(items) > 2 && items[2]&0x40 != 0 {
+			m.Title = "Title"
+		}
+		m.Bordered = len(items) > 2 && items[2]&0x20 != 0
+		m.ScrollBehavior = ScrollBehavior(len(items) % 2)
+	}
+	width, height := 30, 8
+	resize(&m, width, height)
+	for i, b := range steps {
+		step := "resize"
+		if b >= 200 {
+			width, height = 10+int(b-200)*7%50, 2+int(b-200)*5%20
+			m.UpdateInPlace(tea.WindowSizeMsg{Width: width, Height: height})
+		} else {
+			step = fuzzKeys[int(b)%len(fuzzKeys)]
+			press(&m, step)
+		}
+		if err := checkInvariants(m, height); err != nil {
+			return fmt.Errorf("step %d (%s): %w", i, step, err)
+		}
+	}
+	return nil
+}
+
+func FuzzUpdate(f *testing.F) {
+	f.Add([]byte{0, 6, 6, 2, 6, 4}, []byte{1, 1, 3, 0, 10, 1, 1})
+	f.Add([]byte{5, 0x15, 6, 0x10, 6, 3, 6}, []byte{15, 17, 11, 1, 1, 10, 11, 0})
+	f.Add([]byte{0x10, 6, 6, 0, 6, 1, 6, 6, 6}, []byte{200, 1, 1, 1, 7, 10, 201, 0, 0})
+	f.Add([]byte{6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6}, []byte{7, 202, 0, 6, 203, 15, 16, 12})
+	f.Fuzz(func(t *testing.T, items, steps []byte) {
+		if len(items) > 64 || len(steps) > 256 {
+			return
+		}
+		var err error
+		within(t, func() { err = runSteps(items, steps) })
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// TestUpdateRandom runs thousands of random key presses and resizes through
+// pickers of random options, as FuzzUpdate does without a corpus.
+func TestUpdateRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for c := 0; c < 200; c++ {
+		items := make([]byte, r.Intn(40))
+		r.Read(items)
+		steps := make([]byte, 50)
+		r.Read(steps)
+		var err error
+		within(t, func() { err = runSteps(items, steps) })
+		if err != nil {
+			t.Fatalf("items %v, steps %v: %v", items, steps, err)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package options
+
+import "fmt"
+
+// groupOf returns the index of the header starting the group that the option
+// at index i belongs to, or -1 if it isn't in a group.
+func (m Model) groupOf(i int) int {
+	if i >= len(m.Options) {
+		return -1
+	}
+	for ; i >= 0; i-- {
+		if m.item(i).Kind == Header {
+			return i
+		}
+	}
+	return -1
+}
+
+// hasGroups returns whether any of the options is a group header.
+func (m Model) hasGroups() bool {
+	for i := range m.Options {
+		if m.item(i).Kind == Header {
+			return true
+		}
+	}
+	return false
+}
+
+// groupCollapsed returns whether the group started by the header at index i
+// is collapsed.
+func (m Model) groupCollapsed(i int) bool {
+	return i >= 0 && i < len(m.collapsed) && m.collapsed[i]
+}
+
+// groupCount returns the number of selectable options in the group started
+// by the header at index i.
+func (m Model) groupCount(i int) int {
+	var n int
+	for j := i + 1; j < len(m.Options); j++ {
+		switch m.item(j).Kind {
+		case Header:
+			return n
+		case Selectable:
+			n++
+		}
+	}
+	return n
+}
+
+// SetGroupCollapsed collapses or expands the group started by the header at
+// index i of Options. If the cursor is on a member of a group that is being
+// collapsed, it moves to the header.
+func (m *Model) SetGroupCollapsed(i int, v bool) {
+	m.Invalidate()
+	if i < 0 || i >= len(m.Options) || m.item(i).Kind != Header {
+		return
+	}
+	m.setCollapsed(i, v)
+	m.relayout()
+}
+
+// GroupCollapsed returns whether the group started by the header at index i
+// of Options is collapsed.
+func (m Model) GroupCollapsed(i int) bool {
+	return m.groupCollapsed(i)
+}
+
+// setCollapsed records the collapsed state of the header at index i without
+// laying the rows out again.
+func (m *Model) setCollapsed(i int, v bool) {
+	// The collapsed state is shared between copies of the model, so don't
+	// modify it in place.
+	collapsed := make([]bool, len(m.Options))
+	copy(collapsed, m.collapsed)
+	collapsed[i] = v
+	m.collapsed = collapsed
+}
+
+// toggleGroup collapses or expands the group holding the cursor.
+func (m *Model) toggleGroup() {
+	if h := m.groupOf(m.optionIndex(m.cursorIndex())); h != -1 {
+		m.SetGroupCollapsed(h, !m.groupCollapsed(h))
+	}
+}
+
+// headerLabel returns the text rendered for the header at index i, which
+// includes the number of hidden options while the group is collapsed.
+func (m Model) headerLabel(i int, name string) string {
+	if !m.groupCollapsed(i) {
+		return name
+	}
+	return fmt.Sprintf("%s (%d)", name, m.groupCount(i))
+}
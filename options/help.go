@@ -0,0 +1,189 @@
+package options
+
+import (
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// Model can be passed to help.Model.View as it is.
+var _ help.KeyMap = Model{}
+
+// ShortHelp returns the bindings to show in the short help view. While the
+// filter is being edited only the bindings to apply or cancel it are shown.
+// The bindings follow the features in use, such as the Back key in a
+// submenu. It's part of the help.KeyMap interface.
+func (m Model) ShortHelp() []key.Binding {
+	if m.filterState == Filtering {
+		return []key.Binding{
+			m.KeyMap.AcceptWhileFiltering,
+			m.KeyMap.CancelWhileFiltering,
+		}
+	}
+	kb := m.navigationHelp()
+	if m.Depth() > 0 {
+		kb = append(kb, m.KeyMap.Back)
+	}
+	return append(kb, m.filterHelp()...)
+}
+
+// FullHelp returns the bindings to show in the full help view. It's part of
+// the help.KeyMap interface.
+func (m Model) FullHelp() [][]key.Binding {
+	if m.filterState == Filtering {
+		return [][]key.Binding{{
+			m.KeyMap.AcceptWhileFiltering,
+			m.KeyMap.CancelWhileFiltering,
+			m.KeyMap.CycleFilterMode,
+		}}
+	}
+	help := [][]key.Binding{m.navigationHelp()}
+	for _, kb := range [][]key.Binding{m.featureHelp(), m.filterHelp()} {
+		if len(kb) > 0 {
+			help = append(help, kb)
+		}
+	}
+	return help
+}
+
+// navigationHelp returns the bindings that move the cursor and select an
+// option in the current layout.
+func (m Model) navigationHelp() []key.Binding {
+	var kb []key.Binding
+	switch m.Layout {
+	case LayoutHorizontal:
+		kb = []key.Binding{m.KeyMap.Left, m.KeyMap.Right}
+	case LayoutGrid:
+		kb = []key.Binding{m.KeyMap.Up, m.KeyMap.Down, m.KeyMap.Left, m.KeyMap.Right}
+	default:
+		kb = []key.Binding{m.KeyMap.Up, m.KeyMap.Down}
+	}
+	if m.SelectionMode != SelectOne {
+		kb = append(kb, m.KeyMap.Toggle)
+	}
+	return append(kb, m.KeyMap.Select)
+}
+
+// featureHelp returns the bindings of the features in use: paging, trees,
+// groups, submenus, the error banner and retrying a failed load. Bindings shadowed by the layout are
+// left out.
+func (m Model) featureHelp() []key.Binding {
+	var kb []key.Binding
+	if m.Paginated {
+		kb = append(kb, m.KeyMap.PrevPage, m.KeyMap.NextPage)
+	} else if m.tree() && m.Layout == LayoutVertical {
+		kb = append(kb, m.KeyMap.Expand, m.KeyMap.Collapse)
+	}
+	if m.hasGroups() {
+		kb = append(kb, m.KeyMap.ToggleGroup)
+	}
+	if m.Depth() > 0 {
+		kb = append(kb, m.KeyMap.Back)
+	}
+	if m.err != nil {
+		kb = append(kb, m.KeyMap.DismissError)
+	}
+	if m.canRetry() {
+		kb = append(kb, m.KeyMap.Retry)
+	}
+	return kb
+}
+
+// filterHelp returns the filter bindings that apply outside of the filter
+// input. There are none when filtering is disabled, unless a filter has been
+// applied programmatically and can be cleared. A live filter needs no key to
+// start it.
+func (m Model) filterHelp() []key.Binding {
+	var kb []key.Binding
+	if m.KeyMap.Filter.Enabled() && !m.LiveFilter {
+		kb = append(kb, m.KeyMap.Filter)
+	}
+	if m.filterState == FilterApplied {
+		kb = append(kb, m.KeyMap.ClearFilter)
+	}
+	return kb
+}
+
+// HandledKeys returns the keys, as reported by tea.KeyMsg.String, that the
+// picker acts on in its current state, for a parent model to leave them to
+// it. There are none while the picker is blurred. While the filter is being
+// edited, and with LiveFilter, every printable key is taken by the filter
+// as well as the keys listed.
+func (m Model) HandledKeys() []string {
+	if m.blurred {
+		return nil
+	}
+	var kb []key.Binding
+	if m.filterState == Filtering {
+		kb = []key.Binding{
+			m.KeyMap.AcceptWhileFiltering,
+			m.KeyMap.CancelWhileFiltering,
+			m.KeyMap.CycleFilterMode,
+		}
+	} else {
+		kb = m.handledBindings()
+	}
+
+	var keys []string
+	seen := make(map[string]bool)
+	add := func(k string) {
+		if k != "" && !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	for _, b := range kb {
+		if b.Enabled() {
+			for _, k := range b.Keys() {
+				add(k)
+			}
+		}
+	}
+	if m.filterState != Filtering {
+		if m.LiveFilter && m.FilterInput.Value() != "" {
+			add("backspace")
+		}
+		for r := 0; r < m.rowCount(); r++ {
+			add(m.shortcut(r))
+		}
+	}
+	return keys
+}
+
+// handledBindings returns the bindings that handleBrowsing acts on with the
+// features in use.
+func (m Model) handledBindings() []key.Binding {
+	kb := []key.Binding{m.KeyMap.Up, m.KeyMap.Down, m.KeyMap.Select}
+	if m.Layout != LayoutVertical {
+		kb = append(kb, m.KeyMap.Left, m.KeyMap.Right)
+	}
+	if m.SelectionMode != SelectOne {
+		kb = append(kb, m.KeyMap.Toggle)
+	}
+	if m.Paginated {
+		kb = append(kb, m.KeyMap.PrevPage, m.KeyMap.NextPage)
+	}
+	if m.tree() {
+		kb = append(kb, m.KeyMap.Expand, m.KeyMap.Collapse)
+	}
+	if m.hasGroups() {
+		kb = append(kb, m.KeyMap.ToggleGroup)
+	}
+	if m.Depth() > 0 {
+		kb = append(kb, m.KeyMap.Back)
+	}
+	if m.err != nil {
+		kb = append(kb, m.KeyMap.DismissError)
+	}
+	if m.canRetry() {
+		kb = append(kb, m.KeyMap.Retry)
+	}
+	if m.LiveFilter {
+		kb = append(kb, m.KeyMap.CycleFilterMode)
+	} else {
+		kb = append(kb, m.KeyMap.Filter)
+	}
+	if m.filterState == FilterApplied {
+		kb = append(kb, m.KeyMap.ClearFilter)
+	}
+	return kb
+}
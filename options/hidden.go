@@ -0,0 +1,54 @@
+package options
+
+// SelectByValue moves the cursor onto the first option whose value is v, as
+// SetSelected does, and returns whether it's there. A hidden option is shown
+// while the cursor stays on it.
+func (m *Model) SelectByValue(v string) bool {
+	for i := range m.Options {
+		if m.value(i) != v {
+			continue
+		}
+		m.reveal(i)
+		m.SetSelected(i)
+		return m.Index() == i
+	}
+	return false
+}
+
+// hasHidden returns whether any of the options is hidden.
+func (m Model) hasHidden() bool {
+	for _, item := range m.items {
+		if item.Hidden {
+			return true
+		}
+	}
+	return false
+}
+
+// hidden returns whether the option at index i of Options is left out of the
+// rows, being hidden and not revealed.
+func (m Model) hidden(i int) bool {
+	return i != m.revealed && m.item(i).Hidden
+}
+
+// reveal shows the option at index i of Options if it's hidden, in place of
+// any other shown before.
+func (m *Model) reveal(i int) {
+	if !m.item(i).Hidden || m.revealed == i {
+		return
+	}
+	m.Invalidate()
+	m.revealed = i
+	m.relayout()
+}
+
+// unreveal hides the option shown by reveal again once the cursor has left
+// it.
+func (m *Model) unreveal() {
+	if m.revealed == -1 || m.Index() == m.revealed {
+		return
+	}
+	m.Invalidate()
+	m.revealed = -1
+	m.relayout()
+}
@@ -0,0 +1,105 @@
+package options
+
+import (
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// filterIndex holds the labels of the options folded to a single case, for
+// the substring and prefix filter modes to search without folding every
+// label again on each key press. Its entries are checked against the labels
+// searched and folded again when those have changed, so that it follows
+// options being appended, inserted or replaced without being built anew.
+// With FilterNormalize the labels are normalized before they're folded, and
+// positions maps the runes of each back to the runes of its label.
+type filterIndex struct {
+	mu         sync.Mutex
+	normalized bool
+	labels     []string
+	folded     []string
+	positions  [][]int
+}
+
+// usesIndex returns whether the filter searches the labels through the
+// index, which only the built-in substring and prefix modes do while
+// FilterIndex is set.
+func (m Model) usesIndex() bool {
+	if !m.FilterIndex || m.Filter != nil {
+		return false
+	}
+	return m.filterMode == FilterModeSubstring || m.filterMode == FilterModePrefix
+}
+
+// searchIndex matches query against the labels of the options at indexes
+// targets of Options, as SubstringFilter does, or PrefixFilter in the prefix
+// mode. The indexes of the ranks are into targets. With FilterNormalize,
+// query is expected to be normalized already and the matched indexes are
+// into the labels as they are.
+func (m *Model) searchIndex(query string, targets []int) []Rank {
+	if m.filterIndex == nil {
+		m.filterIndex = &filterIndex{}
+	}
+	x := m.filterIndex
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.sync(m.Options, m.FilterNormalize)
+
+	q := string(foldRunes(query))
+	n := utf8.RuneCountInString(q)
+	var result []Rank
+	for j, t := range targets {
+		folded := x.folded[t]
+		at := 0
+		if m.filterMode == FilterModePrefix {
+			if !strings.HasPrefix(folded, q) {
+				continue
+			}
+		} else if at = strings.Index(folded, q); at == -1 {
+			continue
+		}
+		matches := span(utf8.RuneCountInString(folded[:at]), n)
+		if x.normalized {
+			matches = originalIndexes(matches, x.positions[t])
+		}
+		result = append(result, Rank{Index: j, MatchedIndexes: matches})
+	}
+	return result
+}
+
+// sync folds the labels that have changed since they were last folded, all
+// of them if normalized has changed.
+func (x *filterIndex) sync(labels []string, normalized bool) {
+	if x.normalized != normalized {
+		x.normalized = normalized
+		x.labels, x.folded, x.positions = nil, nil, nil
+	}
+	if len(x.labels) > len(labels) {
+		x.labels, x.folded = x.labels[:len(labels)], x.folded[:len(labels)]
+		if normalized {
+			x.positions = x.positions[:len(labels)]
+		}
+	}
+	for i, label := range labels {
+		if i < len(x.labels) && x.labels[i] == label {
+			continue
+		}
+		folded, positions := label, []int(nil)
+		if normalized {
+			folded, positions = normalize(label)
+		}
+		folded = string(foldRunes(folded))
+		if i == len(x.labels) {
+			x.labels = append(x.labels, label)
+			x.folded = append(x.folded, folded)
+			if normalized {
+				x.positions = append(x.positions, positions)
+			}
+			continue
+		}
+		x.labels[i], x.folded[i] = label, folded
+		if normalized {
+			x.positions[i] = positions
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package options
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestFilterIndexDefault(t *testing.T) {
+	if m := New(); !m.FilterIndex {
+		t.Error("FilterIndex unset by default")
+	}
+}
+
+// TestFilterIndexMatchesUnindexed checks the index finds the same options
+// and matched runes as folding every label on each search does, as the
+// options change between searches.
+func TestFilterIndexMatchesUnindexed(t *testing.T) {
+	labels := []string{"Café", "CAFE au lait", "décaféiné", "İstanbul", "straße", "Strasse", "cafeteria"}
+	queries := []string{"caf", "CAFE", "é", "ß", "st", "e", "x"}
+	for _, mode := range []FilterMode{FilterModeSubstring, FilterModePrefix} {
+		for _, normalize := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%v/normalize %t", mode, normalize), func(t *testing.T) {
+				indexed := newTestModel(WithOptions(labels))
+				plain := newTestModel(WithOptions(labels))
+				plain.FilterIndex = false
+				for _, m := range []*Model{&indexed, &plain} {
+					m.SetFilterMode(mode)
+					m.FilterNormalize = normalize
+				}
+				for round := 0; round < 3; round++ {
+					for _, q := range queries {
+						indexed.SetFilterText(q)
+						plain.SetFilterText(q)
+						if got, want := indexed.filtered, plain.filtered; !slices.EqualFunc(got, want, equalRanks) {
+							t.Errorf("round %d, %q: indexed %v, want %v", round, q, got, want)
+						}
+					}
+					// Change the labels under the index between rounds.
+					options := append(slices.Clone(indexed.Options[1:]), "CAFÉ "+fmt.Sprint(round))
+					indexed.SetOptions(options)
+					plain.SetOptions(options)
+				}
+			})
+		}
+	}
+}
+
+func equalRanks(a, b Rank) bool {
+	return a.Index == b.Index && slices.Equal(a.MatchedIndexes, b.MatchedIndexes)
+}
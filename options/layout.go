@@ -0,0 +1,225 @@
+package options
+
+import "strings"
+
+// Layout describes how the options are arranged in the view.
+type Layout int
+
+// Available layouts.
+const (
+	// LayoutVertical shows one option per row.
+	LayoutVertical Layout = iota
+
+	// LayoutHorizontal shows the options next to each other on a single
+	// row, navigated with the Left and Right keys. When they don't fit in
+	// Width, the row scrolls to keep the cursor in view.
+	LayoutHorizontal
+
+	// LayoutGrid shows the options in columns, flowing top to bottom and
+	// then left to right. The Left and Right keys move between columns and
+	// the view scrolls by rows of the grid.
+	LayoutGrid
+)
+
+const (
+	horizontalSeparator = " "
+	gridSeparator       = " "
+	scrollLeftMark      = "‹ "
+	scrollRightMark     = " ›"
+)
+
+// horizontalView renders the options on a single row.
+func (m Model) horizontalView() string {
+	cells, start, end := m.horizontalRun()
+	if start == 0 && end == len(cells) {
+		return strings.Join(cells, horizontalSeparator)
+	}
+
+	var s strings.Builder
+	if start > 0 {
+		s.WriteString(m.Styles.Info.Render(scrollLeftMark))
+	}
+	s.WriteString(strings.Join(cells[start:end], horizontalSeparator))
+	if end < len(cells) {
+		s.WriteString(m.Styles.Info.Render(scrollRightMark))
+	}
+	return s.String()
+}
+
+// horizontalRun renders the options of the horizontal layout, returning
+// them along with the run of rows from start to end, exclusive, that is
+// shown: all of them if they fit in Width, or else the run holding the
+// cursor that fits between the scroll marks.
+func (m Model) horizontalRun() (cells []string, start, end int) {
+	cursor := m.cursorIndex()
+	width := m.rowWidth()
+	n := m.rowCount()
+
+	cells = make([]string, n)
+	total := 0
+	for r := range cells {
+		cells[r] = m.renderRow(r, cursor, width)
+		total += stringWidth(cells[r])
+	}
+	total += (n - 1) * stringWidth(horizontalSeparator)
+	if width <= 0 || total <= width {
+		return cells, 0, n
+	}
+
+	// Leave room for the scroll marks on both sides.
+	avail := width - stringWidth(scrollLeftMark) - stringWidth(scrollRightMark)
+	if avail < 1 {
+		avail = 1
+	}
+	for r := range cells {
+		cells[r] = m.renderRow(r, cursor, avail)
+	}
+
+	// Split the options into runs that fit and show the one with the cursor.
+	for {
+		w := stringWidth(cells[start])
+		end = start + 1
+		for end < n && w+stringWidth(horizontalSeparator)+stringWidth(cells[end]) <= avail {
+			w += stringWidth(horizontalSeparator) + stringWidth(cells[end])
+			end++
+		}
+		if cursor < end || end == n {
+			break
+		}
+		start = end
+	}
+	return cells, start, end
+}
+
+// gridSize returns the number of columns and rows of the grid.
+func (m Model) gridSize() (int, int) {
+	n := m.rowCount()
+	cols := m.Columns
+	if cols <= 0 {
+		cols = 1
+		if width := m.rowWidth(); width > 0 {
+			sep := stringWidth(gridSeparator)
+			cols = (width + sep) / (m.gridCellWidth() + sep)
+		}
+	}
+	if cols > n {
+		cols = n
+	}
+	if cols < 1 {
+		cols = 1
+	}
+	return cols, (n + cols - 1) / cols
+}
+
+// gridCellWidth returns the width of the widest option when rendered in
+// full.
+func (m Model) gridCellWidth() int {
+	if m.cellWidthOK {
+		return m.cellWidth
+	}
+	w := 0
+	for r := 0; r < m.rowCount(); r++ {
+		if rw := stringWidth(m.renderRow(r, -1, 0)); rw > w {
+			w = rw
+		}
+	}
+	return w
+}
+
+// syncGrid measures the width of the cells of the grid for gridSize and the
+// view, which would otherwise render every option for each line asked for.
+func (m *Model) syncGrid() {
+	if m.Layout == LayoutGrid && !m.cellWidthOK {
+		m.cellWidth, m.cellWidthOK = m.gridCellWidth(), true
+	}
+}
+
+// line returns the line of the view that row r is shown on, which is the row
+// of the grid it's in when the options are laid out in a grid.
+func (m Model) line(r int) int {
+	if m.Layout != LayoutGrid {
+		return r
+	}
+	if _, rows := m.gridSize(); rows > 0 {
+		return r % rows
+	}
+	return r
+}
+
+// lineCount returns the number of lines the options take up in full.
+func (m Model) lineCount() int {
+	if m.Layout != LayoutGrid {
+		return m.rowCount()
+	}
+	_, rows := m.gridSize()
+	return rows
+}
+
+// moveColumn moves the cursor d columns across the grid, onto the last
+// option when the column it lands in is shorter.
+func (m *Model) moveColumn(d int) {
+	cols, rows := m.gridSize()
+	cursor := m.cursorIndex()
+	if cursor == -1 {
+		return
+	}
+	col := cursor/rows + d
+	if col < 0 || col >= cols {
+		return
+	}
+	r := cursor + d*rows
+	if n := m.rowCount(); r >= n {
+		r = n - 1
+	}
+	if !m.selectable(r) {
+		if d > 0 {
+			r = m.nextSelectable(r)
+		} else {
+			r = m.prevSelectable(r)
+		}
+		if r == -1 {
+			return
+		}
+	}
+	m.selected = r
+	m.followCursor()
+}
+
+// gridView renders the rows of the grid that are in view.
+func (m Model) gridView() string {
+	cursor := m.cursorIndex()
+	n := m.rowCount()
+	cols, rows := m.gridSize()
+
+	// Cells are truncated to an equal share of Width, or padded to the widest
+	// option when the width is unbounded.
+	width, cellWidth := m.rowWidth(), 0
+	if width > 0 {
+		width = (width - (cols-1)*stringWidth(gridSeparator)) / cols
+		cellWidth = width
+	} else {
+		cellWidth = m.gridCellWidth()
+	}
+
+	var s strings.Builder
+	for line := m.min; line < m.min+m.size && line < rows; line++ {
+		if line > m.min {
+			s.WriteRune('\n')
+		}
+		for col := 0; col < cols; col++ {
+			r := col*rows + line
+			if r >= n {
+				break
+			}
+			cell := m.renderRow(r, cursor, width)
+			if col+1 < cols && r+rows < n {
+				if pad := cellWidth - stringWidth(cell); pad > 0 {
+					cell += strings.Repeat(" ", pad)
+				}
+				cell += gridSeparator
+			}
+			s.WriteString(cell)
+		}
+	}
+	return s.String()
+}
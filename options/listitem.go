@@ -0,0 +1,24 @@
+package options
+
+import "github.com/charmbracelet/bubbles/list"
+
+// FromListItems converts the items of a bubbles list into options. Items
+// implementing list.DefaultItem are labelled with their title and described
+// by their description, and others are labelled with their filter value.
+// A filter value other than the label becomes the value of the option, which
+// the filter matches when FilterFields includes FilterOnValue.
+func FromListItems(items []list.Item) []Option {
+	options := make([]Option, len(items))
+	for i, item := range items {
+		o := Option{Label: item.FilterValue()}
+		if d, ok := item.(list.DefaultItem); ok {
+			o.Label = d.Title()
+			o.Description = d.Description()
+		}
+		if v := item.FilterValue(); v != o.Label {
+			o.Value = v
+		}
+		options[i] = o
+	}
+	return options
+}
@@ -0,0 +1,135 @@
+package options
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// OptionsLoadedMsg carries the options loaded by LoadOptions, or the error
+// the load failed with.
+type OptionsLoadedMsg struct {
+	ID      int
+	Options []Option
+	Err     error
+
+	seq int
+}
+
+// newSpinner returns the spinner shown while the options are loading.
+func newSpinner() spinner.Model {
+	return spinner.New(spinner.WithSpinner(spinner.MiniDot))
+}
+
+// LoadOptions loads the options with load in the background, showing a
+// spinner in their place until they arrive. The options replace the current
+// ones once loaded. A load started before another one finishes supersedes it.
+// A failed load is retried as set by Retry.
+func (m *Model) LoadOptions(load func() ([]Option, error)) tea.Cmd {
+	return m.LoadOptionsCtx(context.Background(), func(context.Context) ([]Option, error) {
+		return load()
+	})
+}
+
+// LoadOptionsCtx is LoadOptions with a context, which is passed on to load.
+// Once the context is done the options load brings are dropped and the load
+// fails with the error of the context, though a cancelled load doesn't show
+// it in the error banner.
+func (m *Model) LoadOptionsCtx(ctx context.Context, load func(context.Context) ([]Option, error)) tea.Cmd {
+	m.Invalidate()
+	m.load, m.loadCtx = load, ctx
+	m.attempt = 1
+	return m.startLoad()
+}
+
+// startLoad starts an attempt at the load set by LoadOptionsCtx.
+func (m *Model) startLoad() tea.Cmd {
+	m.loadSeq++
+	m.loadErr = nil
+	m.streaming = false
+	m.retryAt = time.Time{}
+	id, seq, ctx, load := m.id, m.loadSeq, m.loadCtx, m.load
+	return tea.Batch(m.setLoading(), func() tea.Msg {
+		items, err := load(ctx)
+		if ctx.Err() != nil {
+			items, err = nil, ctx.Err()
+		}
+		return OptionsLoadedMsg{ID: id, Options: items, Err: err, seq: seq}
+	})
+}
+
+// SetLoading shows the spinner in place of the options while v is true, for
+// options loaded outside of LoadOptions. SetItems stops it. The returned
+// command starts the spinner.
+func (m *Model) SetLoading(v bool) tea.Cmd {
+	m.Invalidate()
+	m.retryAt = time.Time{}
+	if !v {
+		m.loading = false
+		return nil
+	}
+	m.loadErr = nil
+	return m.setLoading()
+}
+
+// Loading returns whether the options are loading.
+func (m Model) Loading() bool {
+	return m.loading
+}
+
+// LoadErr returns the error the last load of the options failed with, if
+// any.
+func (m Model) LoadErr() error {
+	return m.loadErr
+}
+
+// setLoading starts showing the spinner, returning the command that starts
+// it unless it is spinning already.
+func (m *Model) setLoading() tea.Cmd {
+	if m.loading {
+		return nil
+	}
+	m.loading = true
+	return m.Spinner.Tick
+}
+
+// handleOptionsLoaded shows the options loaded by LoadOptions, unless a
+// later load has been started since. A failed load returns the command
+// retrying it, if it's to be retried.
+func (m *Model) handleOptionsLoaded(msg OptionsLoadedMsg) tea.Cmd {
+	if msg.ID != m.id || msg.seq != m.loadSeq {
+		return nil
+	}
+	if msg.Err != nil {
+		if cmd := m.retry(); cmd != nil {
+			return cmd
+		}
+		m.loadFailed(msg.Err)
+		return nil
+	}
+	m.err = nil
+	m.SetItems(msg.Options)
+	return nil
+}
+
+// loadFailed stops the loading of the options, which failed with err, and
+// shows err in the error banner unless the load was cancelled.
+func (m *Model) loadFailed(err error) {
+	m.loading = false
+	m.loadErr = err
+	if !errors.Is(err, context.Canceled) {
+		m.err = err
+	}
+}
+
+// loadingView renders the spinner and the message shown while the options
+// are loading.
+func (m Model) loadingView() string {
+	if !m.retryAt.IsZero() {
+		return m.retryingView()
+	}
+	return m.Styles.Loading.Render(m.Spinner.View() + " " + m.LoadingMessage)
+}
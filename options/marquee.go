@@ -0,0 +1,100 @@
+package options
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	defaultMarqueeInterval = 150 * time.Millisecond
+
+	// marqueePause is the number of intervals the marquee rests for at
+	// either end of the label.
+	marqueePause = 8
+)
+
+type marqueeMsg struct {
+	id  int
+	seq int
+}
+
+// marqueeTick returns the command that scrolls the label on the cursor once
+// the marquee interval has passed.
+func (m Model) marqueeTick() tea.Cmd {
+	id, seq := m.id, m.marqueeSeq
+	return tea.Tick(m.MarqueeInterval, func(time.Time) tea.Msg {
+		return marqueeMsg{id: id, seq: seq}
+	})
+}
+
+// handleMarquee scrolls the label on the cursor on to its next offset,
+// returning the command for the one after. Once the cursor has moved, the
+// label of the option it's on starts over from its beginning.
+func (m *Model) handleMarquee(msg marqueeMsg) tea.Cmd {
+	if msg.id != m.id || msg.seq != m.marqueeSeq || !m.Marquee {
+		return nil
+	}
+	if i := m.optionIndex(m.cursorIndex()); i != m.marqueeOption {
+		m.invalidateCursor()
+		m.marqueeOption, m.marqueeOffset, m.marqueeWait = i, 0, marqueePause
+		return m.marqueeTick()
+	}
+
+	over := m.marqueeOverflow()
+	switch {
+	case over <= 0:
+		if m.marqueeOffset == 0 {
+			return m.marqueeTick()
+		}
+		m.marqueeOffset = 0
+	case m.marqueeWait > 0:
+		m.marqueeWait--
+		return m.marqueeTick()
+	case m.marqueeOffset >= over:
+		m.marqueeOffset, m.marqueeWait = 0, marqueePause
+	default:
+		m.marqueeOffset = min(m.marqueeOffset+max(m.MarqueeStep, 1), over)
+		if m.marqueeOffset == over {
+			m.marqueeWait = marqueePause
+		}
+	}
+	m.invalidateCursor()
+	return m.marqueeTick()
+}
+
+// resetMarquee scrolls the label on the cursor back to its beginning if the
+// cursor has moved off the option at index i of Options.
+func (m *Model) resetMarquee(i int) {
+	if m.marqueeOption == -1 && m.marqueeOffset == 0 {
+		// There's nothing to reset, which saves looking for the cursor.
+		return
+	}
+	if m.optionIndex(m.cursorIndex()) != i {
+		m.marqueeOption, m.marqueeOffset = -1, 0
+	}
+}
+
+// marqueeOverflow returns by how many cells the label on the cursor is
+// longer than the room it has, or 0 if it isn't scrolled.
+func (m Model) marqueeOverflow() int {
+	r := m.cursorIndex()
+	width := m.listWidth()
+	if r == -1 || width <= 0 || m.Layout != LayoutVertical || m.wrapping() || m.RenderRow != nil {
+		return 0
+	}
+	name, _, prefix := m.rowText(r, m.Options[m.optionIndex(r)])
+	_, _, secW := m.trailing(r, width, prefix, name)
+	return stringWidth(name) - (width - m.leadWidth(r, prefix) - secW)
+}
+
+// marqueeText returns the part of name, the label of row r, that fits in
+// width cells, scrolled by the marquee when r is on the cursor. It reports
+// whether it was scrolled.
+func (m Model) marqueeText(r int, name string, width int) (string, bool) {
+	if !m.Marquee || r != m.cursorIndex() || m.optionIndex(r) != m.marqueeOption || m.marqueeOffset == 0 {
+		return name, false
+	}
+	name, _ = truncate(skipCells(name, m.marqueeOffset), width, "")
+	return name, true
+}
@@ -0,0 +1,93 @@
+package options
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// menu holds the parts of the model that a submenu replaces, so they can be
+// restored when the submenu is popped.
+type menu struct {
+	options   []string
+	items     []Option
+	nodes     nodes
+	collapsed []bool
+	checked   checkSet
+	rows      []int
+	revealed  int
+	styles    Styles
+	keyMap    KeyMap
+	provider  Provider
+	provided  [][]Option
+}
+
+// PushMenu shows items as a submenu of the current options. Non-nil styles
+// and keyMap replace Styles and KeyMap while the submenu is shown; nil ones
+// are inherited from the parent level. Everything is restored by PopMenu.
+// The returned command slides the submenu in when SlideDuration is set.
+func (m *Model) PushMenu(items []Option, styles *Styles, keyMap *KeyMap) tea.Cmd {
+	m.Invalidate()
+	from := m.listView()
+	m.resetFilter()
+	m.pushView()
+	// The levels are shared between copies of the model, so don't append to
+	// them in place.
+	m.menus = append(m.menus[:len(m.menus):len(m.menus)], menu{
+		options:   m.Options,
+		items:     m.items,
+		nodes:     m.nodes,
+		collapsed: m.collapsed,
+		checked:   m.checked,
+		rows:      m.rows,
+		revealed:  m.revealed,
+		styles:    m.Styles,
+		keyMap:    m.KeyMap,
+		provider:  m.provider,
+		provided:  m.provided,
+	})
+	if styles != nil {
+		m.Styles = *styles
+	}
+	if keyMap != nil {
+		m.KeyMap = *keyMap
+	}
+	m.SetItems(items)
+	return m.startSlide(from, false)
+}
+
+// PopMenu returns to the parent of the current submenu, restoring its
+// options, cursor, window, styles and key bindings, and clearing any filter
+// of the submenu. It reports whether there was a submenu to leave. Only the
+// Back key slides the parent back in.
+func (m *Model) PopMenu() bool {
+	m.Invalidate()
+	if len(m.menus) == 0 {
+		return false
+	}
+	// A filter belongs to the submenu's options, as PushMenu cleared the
+	// parent's.
+	m.resetFilter()
+	parent := m.menus[len(m.menus)-1]
+	m.menus = m.menus[:len(m.menus)-1]
+
+	m.Options = parent.options
+	m.items = parent.items
+	m.nodes = parent.nodes
+	m.collapsed = parent.collapsed
+	m.checked = parent.checked
+	m.setRows(parent.rows)
+	m.revealed = parent.revealed
+	m.Styles = parent.styles
+	m.KeyMap = parent.keyMap
+	// The fetches running when the submenu was pushed were dropped, so the
+	// rows they were for are fetched again.
+	m.provider, m.provided, m.requested = parent.provider, parent.provided, nil
+	m.providerSeq++
+	m.popView()
+	// The picker may have been resized since the window was saved.
+	m.fitWindow()
+	return true
+}
+
+// Depth returns how many submenus deep the picker currently is, where 0 is
+// the root menu.
+func (m Model) Depth() int {
+	return len(m.menus)
+}
@@ -0,0 +1,49 @@
+package options
+
+import (
+	"strconv"
+	"strings"
+)
+
+// numberView renders the number shown before row r, right-aligned to the
+// width of the largest number. Rows that can't be selected get a blank
+// column instead. It is empty unless ShowNumbers is set.
+func (m Model) numberView(r int) string {
+	if !m.ShowNumbers {
+		return ""
+	}
+	i := m.optionIndex(r)
+	n, total := 0, 0
+	switch {
+	case len(m.items) == 0:
+		// Every option can be selected, so there's nothing to count.
+		n, total = i+1, len(m.Options)
+		if m.NumberShownOrder {
+			n, total = r+1, m.rowCount()
+		}
+	case m.NumberShownOrder:
+		for j := 0; j < m.rowCount(); j++ {
+			if m.item(m.optionIndex(j)).Kind == Selectable {
+				if j <= r {
+					n++
+				}
+				total++
+			}
+		}
+	default:
+		for j := range m.Options {
+			if m.item(j).Kind == Selectable {
+				if j <= i {
+					n++
+				}
+				total++
+			}
+		}
+	}
+	digits := len(strconv.Itoa(total))
+	if m.item(i).Kind != Selectable {
+		return m.styleFor(r, m.Styles.Option).Render(strings.Repeat(" ", digits+2))
+	}
+	num := strconv.Itoa(n)
+	return m.styleFor(r, m.Styles.Number).Render(strings.Repeat(" ", digits-len(num)) + num + ". ")
+}
@@ -0,0 +1,66 @@
+package options
+
+// Opt sets up a model made by New or NewWithRenderer.
+type Opt func(*Model)
+
+// WithID sets the identifier of the picker in place of a unique one, for
+// example for deterministic tests. Pickers sharing an identifier react to
+// each other's messages.
+func WithID(id int) Opt {
+	return func(m *Model) {
+		m.id = id
+	}
+}
+
+// WithOptions sets the options of the picker.
+func WithOptions(options []string) Opt {
+	return func(m *Model) {
+		m.Options = options
+	}
+}
+
+// WithItems sets the options of the picker from structured entries, as
+// SetItems does.
+func WithItems(items []Option) Opt {
+	return func(m *Model) {
+		m.SetItems(items)
+	}
+}
+
+// WithHeight sets the number of lines the options and the lines below them
+// take up, in place of sizing them to the window, as SetHeight does.
+func WithHeight(h int) Opt {
+	return func(m *Model) {
+		m.SetHeight(h)
+	}
+}
+
+// WithWidth sets the number of cells each row may take up, in place of
+// sizing them to the window.
+func WithWidth(w int) Opt {
+	return func(m *Model) {
+		m.Width = w
+		m.AutoWidth = false
+	}
+}
+
+// WithKeyMap sets the key bindings of the picker.
+func WithKeyMap(km KeyMap) Opt {
+	return func(m *Model) {
+		m.KeyMap = km
+	}
+}
+
+// WithStyles sets the styles of the picker.
+func WithStyles(s Styles) Opt {
+	return func(m *Model) {
+		m.Styles = s
+	}
+}
+
+// WithCursor sets the cursor shown before the option it's on.
+func WithCursor(cursor string) Opt {
+	return func(m *Model) {
+		m.SetCursor(cursor)
+	}
+}
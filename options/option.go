@@ -0,0 +1,454 @@
+package options
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Kind describes how an option behaves in the picker.
+type Kind int
+
+// Available option kinds.
+const (
+	// Selectable is a regular option that can be highlighted and selected.
+	Selectable Kind = iota
+
+	// Info is a plain informational row. It takes up a line in the view but
+	// is skipped by navigation and is never reported as a selection.
+	Info
+
+	// Header starts a group made up of the options that follow it, up to the
+	// next header. Groups can be collapsed to hide their members.
+	Header
+)
+
+// Option is a structured entry in the picker. Entries assigned through
+// Model.Options are Selectable options labelled with their string.
+type Option struct {
+	Label string
+	Kind  Kind
+
+	// Value identifies the option. It defaults to the label.
+	Value string
+
+	// Description is secondary text describing the option.
+	Description string
+
+	// Icon is shown before the label, separated from it by a space.
+	Icon string
+
+	// Children are nested options. When any option has children the picker
+	// renders as a tree in which each parent can be expanded and collapsed.
+	Children []Option
+
+	// Expanded sets whether the children of this option are initially shown.
+	Expanded bool
+
+	// Collapsed sets whether the group started by a Header is initially
+	// collapsed.
+	Collapsed bool
+
+	// Disabled options are shown greyed out and can't hold the cursor.
+	Disabled bool
+
+	// Hidden options aren't shown or filtered, and navigation skips them.
+	// Model.SelectByValue and Model.ApplyState can still put the cursor on
+	// one, which is then shown until the cursor leaves it.
+	Hidden bool
+
+	// Shortcut is a key, as reported by tea.KeyMsg.String, that selects the
+	// option wherever the cursor is. It's shown dimmed at the right of the
+	// row when there's room for it.
+	Shortcut string
+
+	// Cells are the texts shown in the Model.TableColumns, in order. Options
+	// without cells show their label in the first column.
+	Cells []string
+
+	// Checked sets whether the option is initially checked in the
+	// SelectMany and SelectRadio modes.
+	Checked bool
+}
+
+// SetItems sets the options of the picker from structured entries and moves
+// the cursor back to the first selectable option. Nested children are
+// flattened into Options in depth-first order.
+func (m *Model) SetItems(items []Option) {
+	flat, ns := flatten(items)
+	options := make([]string, len(flat))
+	for i, item := range flat {
+		options[i] = item.Label
+	}
+	m.setItems(options, flat, ns)
+}
+
+// setItems sets Options to options, with the flattened entries and tree
+// nodes they come with, which may be nil for options without metadata.
+func (m *Model) setItems(options []string, items []Option, ns nodes) {
+	m.Invalidate()
+	m.loading = false
+	m.loadErr = nil
+	m.retryAt = time.Time{}
+	m.Options, m.items, m.nodes = options, items, ns
+	// Fetches from the Provider, if any, refer to the previous options.
+	m.provider, m.provided, m.requested = nil, nil, nil
+	m.providerSeq++
+	m.revealed = -1
+	m.collapsed = nil
+	m.checked = nil
+	for i, item := range m.items {
+		if item.Kind == Header && item.Collapsed {
+			m.setCollapsed(i, true)
+		}
+		if item.Checked {
+			m.SetChecked(i, true)
+		}
+	}
+	if m.filterActive() {
+		if m.FilterAsync == nil {
+			// The results refer to the previous options.
+			m.filtered = nil
+			m.refilter()
+			return
+		}
+		// Results from FilterAsync refer to the previous options.
+		m.filterQuery = ""
+		m.filtered = nil
+	}
+	m.resetRows()
+}
+
+// SetOptions sets the options of the picker to Selectable options labelled
+// with options, as SetItems does. Unlike assigning Options, it also clears
+// what was kept on the options before, such as the checked ones.
+func (m *Model) SetOptions(options []string) {
+	// Options without metadata need no entries, which would take up more
+	// than the labels themselves on long lists.
+	m.setItems(append([]string{}, options...), nil, nil)
+}
+
+// SetOptionsFromMap sets the options from values, which maps the value of
+// each option to its label. The options are shown in order, then the ones
+// order leaves out sorted by value. It fails, leaving the options as they
+// are, if order holds a value twice or one that values doesn't.
+func (m *Model) SetOptionsFromMap(values map[string]string, order []string) error {
+	items := make([]Option, 0, len(values))
+	placed := make(map[string]bool, len(order))
+	for _, v := range order {
+		label, ok := values[v]
+		switch {
+		case !ok:
+			return fmt.Errorf("options: %q is ordered but has no label", v)
+		case placed[v]:
+			return fmt.Errorf("options: %q is ordered twice", v)
+		}
+		placed[v] = true
+		items = append(items, Option{Label: label, Value: v})
+	}
+	rest := make([]string, 0, len(values)-len(order))
+	for v := range values {
+		if !placed[v] {
+			rest = append(rest, v)
+		}
+	}
+	sort.Strings(rest)
+	for _, v := range rest {
+		items = append(items, Option{Label: values[v], Value: v})
+	}
+	m.SetItems(items)
+	return nil
+}
+
+// resetRows recomputes the shown rows and moves the cursor back to the first
+// selectable one.
+func (m *Model) resetRows() {
+	m.setRows(m.computeRows())
+	m.min = 0
+	m.selected = 0
+	if r := m.cursorIndex(); r != -1 {
+		m.selected = r
+	}
+	m.followCursor()
+}
+
+// setRows sets the rows shown, computed for the options as they are.
+func (m *Model) setRows(rows []int) {
+	m.rows = rows
+	m.rowsFor = len(m.Options)
+	m.cellWidthOK, m.selectableOK = false, false
+}
+
+// dropStaleRows drops the rows of options past the end of Options, as when
+// it's shrunk directly, along with the filter results showing them. The
+// cursor stays on its row, or moves past the last one for repair to clamp
+// when its row is dropped.
+func (m *Model) dropStaleRows() {
+	n := len(m.Options)
+	if m.rows == nil || n >= m.rowsFor {
+		return
+	}
+	rows := make([]int, 0, len(m.rows))
+	selected := m.selected
+	for r, i := range m.rows {
+		switch {
+		case i < n:
+			rows = append(rows, i)
+		case r == m.selected:
+			selected = len(m.rows)
+		case r < m.selected:
+			selected--
+		}
+	}
+	if m.filtered != nil {
+		filtered := make([]Rank, 0, len(rows))
+		for _, f := range m.filtered {
+			if f.Index < n {
+				filtered = append(filtered, f)
+			}
+		}
+		m.filtered = filtered
+		m.filterTotal = len(m.filterTargets())
+	}
+	if m.revealed >= n {
+		m.revealed = -1
+	}
+	m.setRows(rows)
+	m.selected = min(selected, len(rows))
+}
+
+// Items returns the options of the picker as structured entries. In tree
+// mode the children are nested under their parents again.
+func (m Model) Items() []Option {
+	if m.tree() {
+		return m.nodes.unflatten(m.flatItems())
+	}
+	return m.flatItems()
+}
+
+// flatItems returns every option as a flat list of structured entries.
+func (m Model) flatItems() []Option {
+	items := make([]Option, len(m.Options))
+	for i := range m.Options {
+		items[i] = m.item(i)
+	}
+	return items
+}
+
+// item returns the structured entry at index i of Options. Options without
+// metadata are treated as Selectable.
+func (m Model) item(i int) Option {
+	var item Option
+	if i < len(m.items) {
+		item = m.items[i]
+	} else if m.provided != nil {
+		item = m.providedItem(i)
+	}
+	item.Label = m.Options[i]
+	return item
+}
+
+// value returns the value of the option at index i of Options, which
+// defaults to its label.
+func (m Model) value(i int) string {
+	if v := m.item(i).Value; v != "" {
+		return v
+	}
+	return m.Options[i]
+}
+
+// rowCount returns the number of rows that can currently be shown.
+func (m Model) rowCount() int {
+	if m.rows != nil {
+		return len(m.rows)
+	}
+	return len(m.Options)
+}
+
+// optionIndex returns the index in Options of the option shown on row r, or
+// -1 if there is no such row.
+func (m Model) optionIndex(r int) int {
+	if r < 0 || r >= m.rowCount() {
+		return -1
+	}
+	if m.rows != nil {
+		r = m.rows[r]
+	}
+	if r >= len(m.Options) {
+		return -1
+	}
+	return r
+}
+
+// rowOf returns the row showing the option at index i of Options, or -1 if
+// it isn't visible.
+func (m Model) rowOf(i int) int {
+	if m.rows == nil {
+		if i < 0 || i >= len(m.Options) {
+			return -1
+		}
+		return i
+	}
+	for r, j := range m.rows {
+		if i == j {
+			return r
+		}
+	}
+	return -1
+}
+
+// computeRows returns the indexes of the options that are currently shown,
+// or nil when all of them are.
+func (m Model) computeRows() []int {
+	if m.filterActive() {
+		rows := make([]int, len(m.filtered))
+		for r, f := range m.filtered {
+			rows[r] = f.Index
+		}
+		return rows
+	}
+
+	tree := m.tree()
+	if !tree && m.collapsed == nil && !m.hasHidden() {
+		return nil
+	}
+
+	rows := make([]int, 0, len(m.Options))
+	visible := make([]bool, len(m.Options))
+	folded := false
+	for i := range m.Options {
+		header := m.item(i).Kind == Header
+		if header {
+			folded = false
+		}
+		if tree {
+			n := m.nodes[i]
+			if n.parent != -1 && (!visible[n.parent] || !m.nodes[n.parent].expanded) {
+				continue
+			}
+		}
+		if folded || m.hidden(i) {
+			continue
+		}
+		visible[i] = true
+		rows = append(rows, i)
+		if header {
+			folded = m.groupCollapsed(i)
+		}
+	}
+	return rows
+}
+
+// relayout recomputes the shown rows, keeping the cursor on the option it
+// was on. If that option has been hidden, the cursor moves to the header of
+// its collapsed group or to its closest visible ancestor.
+func (m *Model) relayout() {
+	cursor := m.optionIndex(m.cursorIndex())
+	m.setRows(m.computeRows())
+
+	m.selected = 0
+	for cursor != -1 {
+		if r := m.rowOf(cursor); r != -1 {
+			m.selected = r
+			break
+		}
+		// A header that's hidden itself, under a collapsed parent, falls
+		// back on the parent rather than on itself.
+		if h := m.groupOf(cursor); h != -1 && h != cursor && m.groupCollapsed(h) {
+			cursor = h
+		} else if m.tree() {
+			cursor = m.nodes[cursor].parent
+		} else {
+			cursor = -1
+		}
+	}
+	// A collapsed header the cursor falls back on may not hold it, so scroll
+	// to the row it's shown on instead.
+	if r := m.cursorIndex(); r != -1 {
+		m.selected = r
+	}
+	m.followCursor()
+
+	// Pull the window back up if rows disappearing left it hanging past the
+	// end of the list.
+	if !m.Paginated && !m.wrapping() {
+		m.clampWindow()
+	}
+}
+
+// clampWindow scrolls the window back up, keeping its size, if it's
+// scrolled past the end of the list, leaving lines below the last one blank
+// while lines above the first one shown could fill them.
+func (m *Model) clampWindow() {
+	if last := m.lineCount() - 1; m.min+m.size-1 > last && m.min > 0 {
+		m.min -= min(m.min+m.size-1-last, m.min)
+	}
+}
+
+// selectable returns whether row r can hold the cursor.
+func (m Model) selectable(r int) bool {
+	i := m.optionIndex(r)
+	if i == -1 {
+		return false
+	}
+	item := m.item(i)
+	if item.Disabled {
+		return false
+	}
+	switch item.Kind {
+	case Selectable:
+		return true
+	case Header:
+		return m.SelectableHeaders
+	}
+	return false
+}
+
+// nextSelectable returns the first selectable row after r, or -1 if there is
+// none.
+func (m Model) nextSelectable(r int) int {
+	for j := r + 1; j < m.rowCount(); j++ {
+		if m.selectable(j) {
+			return j
+		}
+	}
+	return -1
+}
+
+// prevSelectable returns the first selectable row before r, or -1 if there is
+// none.
+func (m Model) prevSelectable(r int) int {
+	if n := m.rowCount(); r > n {
+		r = n
+	}
+	for j := r - 1; j >= 0; j-- {
+		if m.selectable(j) {
+			return j
+		}
+	}
+	return -1
+}
+
+// clampedSelected returns the row the cursor is on, as cursorIndex does, or
+// -1 if the selected row is no longer one of the rows, as when Options is
+// shrunk directly, so that the cursor isn't taken for being on an option it
+// was never put on. View and Update move it back onto the rows first.
+func (m Model) clampedSelected() int {
+	if m.selected < 0 || m.selected >= m.rowCount() || m.optionIndex(m.selected) == -1 {
+		return -1
+	}
+	return m.cursorIndex()
+}
+
+// cursorIndex returns the row the cursor is effectively on. When the selected
+// row can't hold the cursor, the nearest selectable row is used instead. It
+// returns -1 when nothing is selectable.
+func (m Model) cursorIndex() int {
+	if m.selectable(m.selected) {
+		return m.selected
+	}
+	if next := m.nextSelectable(m.selected); next != -1 {
+		return next
+	}
+	return m.prevSelectable(m.selected)
+}
@@ -0,0 +1,62 @@
+package options
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSelectAfterOptionsShrink(t *testing.T) {
+	m := New(WithOptions([]string{"o0", "o1", "o2", "o3", "o4", "o5", "o6", "o7", "o8", "o9"}))
+	m.UpdateInPlace(tea.WindowSizeMsg{Width: 20, Height: 20})
+	for i := 0; i < 8; i++ {
+		m.UpdateInPlace(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	m.Options = []string{"o0", "o1", "o2"}
+
+	enter := tea.KeyMsg{Type: tea.KeyEnter}
+	if ok, option := m.DidSelectOption(enter); ok {
+		t.Fatalf("DidSelectOption = %q after the option on the cursor was removed", option)
+	}
+	m.UpdateInPlace(enter)
+	if m.WasSubmitted() {
+		t.Fatal("enter selected an option after the one on the cursor was removed")
+	}
+	if ok, option := m.DidSelectOption(enter); !ok || option != "o2" {
+		t.Errorf("DidSelectOption = %v, %q once the cursor is back on the list, want o2", ok, option)
+	}
+}
+
+func TestSelectAfterFilteredOptionsShrink(t *testing.T) {
+	m := New(WithOptions([]string{"o0", "o1", "o2", "o3", "o4", "o5", "o6", "o7"}))
+	m.UpdateInPlace(tea.WindowSizeMsg{Width: 20, Height: 20})
+	m.SetFilterText("o")
+	for i := 0; i < 4; i++ {
+		m.UpdateInPlace(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	m.Options = []string{"o0", "o1"}
+
+	enter := tea.KeyMsg{Type: tea.KeyEnter}
+	m.UpdateInPlace(enter)
+	if m.WasSubmitted() {
+		t.Fatal("enter selected an option after the one on the cursor was removed")
+	}
+	if got := m.FilteredOptions(); len(got) != 2 {
+		t.Errorf("FilteredOptions() = %q after shrinking to 2 options", got)
+	}
+	if ok, option := m.DidSelectOption(enter); !ok || option != "o1" {
+		t.Errorf("DidSelectOption = %v, %q once the cursor is back on the list, want o1", ok, option)
+	}
+}
+
+func TestVisibleOptionsAfterOptionsShrink(t *testing.T) {
+	m := New(WithOptions([]string{"a x b", "a x b", "a x b", "a x b", "ab", "ab"}))
+	m.UpdateInPlace(tea.WindowSizeMsg{Width: 20, Height: 20})
+	m.SetFilterText("ab")
+	m.Options = m.Options[:3]
+
+	// The rows of the options gone are left out before View repairs them.
+	if got := m.VisibleOptions(); len(got) != 3 {
+		t.Errorf("VisibleOptions() = %q after shrinking to 3 options", got)
+	}
+}
@@ -1,13 +1,26 @@
 package options
 
 import (
+	"context"
 	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/paginator"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/reflow/ansi"
+	"github.com/muesli/reflow/wordwrap"
+	"github.com/muesli/reflow/wrap"
 )
 
 var (
@@ -23,235 +36,1855 @@ func nextID() int {
 	return lastID
 }
 
-// New returns a new filepicker model with default styling and key bindings.
-func New() Model {
-	return Model{
-		id:            nextID(),
-		Options:       []string{},
-		Cursor:        ">",
-		selected:      0,
-		AutoHeight:    true,
-		Height:        0,
-		max:           0,
-		min:           0,
-		selectedStack: newStack(),
-		minStack:      newStack(),
-		maxStack:      newStack(),
-		KeyMap:        DefaultKeyMap(),
-		Styles:        DefaultStyles(),
+// ResetIDsForTesting restarts the identifiers given to new pickers from one,
+// so that tests can expect the same identifiers in the messages of pickers
+// whatever other tests ran first. Pickers made before and after it may share
+// an identifier, so it's only meant for tests. WithID sets the identifier of
+// a single picker instead.
+func ResetIDsForTesting() {
+	idMtx.Lock()
+	defer idMtx.Unlock()
+	lastID = 0
+}
+
+// New returns a new filepicker model with default styling and key bindings,
+// changed by opts.
+func New(opts ...Opt) Model {
+	return NewWithRenderer(lipgloss.DefaultRenderer(), opts...)
+}
+
+// NewWithRenderer returns a new filepicker model with default styling and key
+// bindings, changed by opts, rendering with a given Lip Gloss renderer. Use
+// it to respect the color profile of each session in a Wish server.
+func NewWithRenderer(r *lipgloss.Renderer, opts ...Opt) Model {
+	m := Model{
+		id:                    nextID(),
+		renderer:              r,
+		Accessible:            os.Getenv("ACCESSIBLE") != "",
+		Options:               []string{},
+		Cursor:                ">",
+		Ellipsis:              "…",
+		LineBreak:             " ",
+		TabWidth:              4,
+		EmptyMessage:          "Bummer. No Options Provided.",
+		Spinner:               newSpinner(),
+		LoadingMessage:        "Loading options…",
+		StreamingMessage:      "%s options loaded…",
+		RetryingMessage:       "Retrying in %s… (attempt %d/%d)",
+		AutoSelectMessage:     "Auto-selecting '%s' in %s",
+		StatusMessageLifetime: defaultStatusMessageLifetime,
+		CursorBlinkInterval:   defaultCursorBlinkInterval,
+		MarqueeInterval:       defaultMarqueeInterval,
+		MarqueeStep:           1,
+		marqueeOption:         -1,
+		revealed:              -1,
+		Glyphs:                DefaultGlyphs(),
+		selected:              0,
+		AutoHeight:            true,
+		AutoWidth:             true,
+		ManagedSize:           true,
+		FilterIndex:           true,
+		Height:                0,
+		min:                   0,
+		KeyMap:                DefaultKeyMap(),
+		Styles:                DefaultStylesWithRenderer(r),
+		FilterInput:           newFilterInput(r),
+		Paginator:             newPaginator(),
+		cache:                 &viewCache{},
 	}
+	for _, opt := range opts {
+		opt(&m)
+	}
+	m.sizeWindow()
+	return m
 }
 
 type errorMsg struct {
+	id  int
 	err error
 }
 
 const (
-	marginBottom  = 5
 	fileSizeWidth = 8
 	paddingLeft   = 2
 )
 
 // KeyMap defines key bindings for each user action.
 type KeyMap struct {
-	Down   key.Binding
-	Up     key.Binding
-	Select key.Binding
+	Down     key.Binding
+	Up       key.Binding
+	Select   key.Binding
+	Toggle   key.Binding
+	Expand   key.Binding
+	Collapse key.Binding
+	Left     key.Binding
+	Right    key.Binding
+	PrevPage key.Binding
+	NextPage key.Binding
+
+	ToggleGroup  key.Binding
+	Back         key.Binding
+	DismissError key.Binding
+	Retry        key.Binding
+
+	Filter               key.Binding
+	ClearFilter          key.Binding
+	CancelWhileFiltering key.Binding
+	AcceptWhileFiltering key.Binding
+	CycleFilterMode      key.Binding
 }
 
 // DefaultKeyMap defines the default keybindings.
 func DefaultKeyMap() KeyMap {
 	return KeyMap{
-		Down:   key.NewBinding(key.WithKeys("j", "down", "ctrl+n"), key.WithHelp("j", "down")),
-		Up:     key.NewBinding(key.WithKeys("k", "up", "ctrl+p"), key.WithHelp("k", "up")),
-		Select: key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "select")),
+		Down:     key.NewBinding(key.WithKeys("j", "down", "ctrl+n"), key.WithHelp("j", "down")),
+		Up:       key.NewBinding(key.WithKeys("k", "up", "ctrl+p"), key.WithHelp("k", "up")),
+		Select:   key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "select")),
+		Toggle:   key.NewBinding(key.WithKeys(" ", "x"), key.WithHelp("space", "toggle")),
+		Expand:   key.NewBinding(key.WithKeys("l", "right"), key.WithHelp("l", "expand")),
+		Collapse: key.NewBinding(key.WithKeys("h", "left"), key.WithHelp("h", "collapse")),
+		Left:     key.NewBinding(key.WithKeys("h", "left"), key.WithHelp("h", "left")),
+		Right:    key.NewBinding(key.WithKeys("l", "right"), key.WithHelp("l", "right")),
+		PrevPage: key.NewBinding(key.WithKeys("h", "left", "pgup"), key.WithHelp("h", "prev page")),
+		NextPage: key.NewBinding(key.WithKeys("l", "right", "pgdown"), key.WithHelp("l", "next page")),
+
+		ToggleGroup:  key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "toggle group")),
+		Back:         key.NewBinding(key.WithKeys("backspace", "esc"), key.WithHelp("esc", "back")),
+		DismissError: key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "dismiss error")),
+		Retry:        key.NewBinding(key.WithKeys("ctrl+r"), key.WithHelp("ctrl+r", "retry")),
+
+		Filter:               key.NewBinding(key.WithKeys("/"), key.WithHelp("/", "filter")),
+		ClearFilter:          key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "clear filter")),
+		CancelWhileFiltering: key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "cancel")),
+		AcceptWhileFiltering: key.NewBinding(key.WithKeys("enter", "tab"), key.WithHelp("enter", "apply filter")),
+		CycleFilterMode:      key.NewBinding(key.WithKeys("ctrl+t"), key.WithHelp("ctrl+t", "filter mode")),
 	}
 }
 
 // Styles defines the possible customizations for styles in the file picker.
+// The styles of the rows take what they leave unset from Option.
 type Styles struct {
+	Title lipgloss.Style
+
+	// Border frames the picker when Model.Bordered is set. Its top edge is
+	// drawn with the title in it.
+	Border lipgloss.Style
+
+	// Cursor styles the cursor while the picker is focused, and
+	// DisabledCursor while it's blurred.
 	DisabledCursor lipgloss.Style
 	Cursor         lipgloss.Style
-	Option         lipgloss.Style
-	Selected       lipgloss.Style
+
+	// Blurred styles the rows while the picker is blurred, when
+	// Model.DimBlurred is set. What it leaves unset is taken from the
+	// style of each row.
+	Blurred lipgloss.Style
+
+	Option    lipgloss.Style
+	OptionAlt lipgloss.Style
+	Selected  lipgloss.Style
+	Disabled  lipgloss.Style
+
+	// Checked and CheckedSelected style the checked options, off and on
+	// the cursor, in the SelectMany and SelectRadio modes. What
+	// CheckedSelected leaves unset is taken from Selected, then Checked.
+	Checked         lipgloss.Style
+	CheckedSelected lipgloss.Style
+
+	Info           lipgloss.Style
+	Header         lipgloss.Style
+	FilterPrompt   lipgloss.Style
+	FilterCursor   lipgloss.Style
+	FilterMatch    lipgloss.Style
+	NoMatches      lipgloss.Style
+	FilterPending  lipgloss.Style
+	FilterError    lipgloss.Style
+	FilterCount    lipgloss.Style
+	FilterMode     lipgloss.Style
+	ScrollbarThumb lipgloss.Style
+	ScrollbarTrack lipgloss.Style
+	Overflow       lipgloss.Style
+	Number         lipgloss.Style
+	StatusBar      lipgloss.Style
+	StatusMessage  lipgloss.Style
+	Description    lipgloss.Style
+	Secondary      lipgloss.Style
+	Shortcut       lipgloss.Style
+
+	// ColumnHeader renders the titles of the Columns above the options.
+	ColumnHeader lipgloss.Style
+
+	Pagination            lipgloss.Style
+	ActivePaginationDot   lipgloss.Style
+	InactivePaginationDot lipgloss.Style
+
+	// Empty renders EmptyMessage when there are no options.
+	Empty lipgloss.Style
+
+	// Loading renders the spinner and LoadingMessage while the options are
+	// loading, and Error the error loading them failed with.
+	Loading lipgloss.Style
+	Error   lipgloss.Style
+
+	// Deprecated: use Empty and Model.EmptyMessage. A string set on it
+	// with SetString is still shown in place of EmptyMessage.
 	EmptyDirectory lipgloss.Style
 }
 
+// The colors of the default styles, for dark and light backgrounds.
+var (
+	accentColor   = lipgloss.AdaptiveColor{Light: "163", Dark: "212"}
+	headerColor   = lipgloss.AdaptiveColor{Light: "56", Dark: "99"}
+	errorColor    = lipgloss.AdaptiveColor{Light: "160", Dark: "196"}
+	checkedColor  = lipgloss.AdaptiveColor{Light: "28", Dark: "78"}
+	dimColor      = lipgloss.AdaptiveColor{Light: "242", Dark: "247"}
+	disabledColor = lipgloss.AdaptiveColor{Light: "246", Dark: "243"}
+	subtleColor   = lipgloss.AdaptiveColor{Light: "243", Dark: "244"}
+	faintColor    = lipgloss.AdaptiveColor{Light: "248", Dark: "240"}
+)
+
 // DefaultStyles defines the default styling for the file picker.
 func DefaultStyles() Styles {
 	return DefaultStylesWithRenderer(lipgloss.DefaultRenderer())
 }
 
 // DefaultStylesWithRenderer defines the default styling for the file picker,
-// with a given Lip Gloss renderer.
+// with a given Lip Gloss renderer. The colors adapt to the background that
+// the renderer detects.
 func DefaultStylesWithRenderer(r *lipgloss.Renderer) Styles {
-	return Styles{
-		DisabledCursor: r.NewStyle().Foreground(lipgloss.Color("247")),
-		Cursor:         r.NewStyle().Foreground(lipgloss.Color("212")),
-		Option:         r.NewStyle(),
-		Selected:       r.NewStyle().Foreground(lipgloss.Color("212")).Bold(true),
-		EmptyDirectory: r.NewStyle().Foreground(lipgloss.Color("240")).PaddingLeft(paddingLeft).SetString("Bummer. No Options Provided."),
-	}
+	return paletteStyles(r, defaultPalette)
 }
 
-// Model represents a file picker.
+// Model represents a file picker. It's a value: copies of it, such as the
+// ones Update returns, can be changed independently of each other.
 type Model struct {
 	id int
 
+	// Title is shown above the options when set.
+	Title string
+
 	Options []string
+	items   []Option
+	nodes   nodes
+
+	// collapsed holds the collapsed state of group headers by their index in
+	// Options.
+	collapsed []bool
+
+	// SelectableHeaders lets the cursor land on group headers, where the
+	// Select key collapses and expands the group.
+	SelectableHeaders bool
+
+	// StickyHeaders pins the header of the group scrolling past the top of
+	// the window over its first row, until the next header reaches it. It
+	// applies to the vertical layout while the options aren't paginated or
+	// wrapped.
+	StickyHeaders bool
+
+	// rows holds the indexes of the options that are currently shown, in
+	// display order. It is nil when every option is shown.
+	rows []int
+	// rowsFor is the number of options rows was computed for, to tell when
+	// Options has been shrunk under it.
+	rowsFor int
+
+	// revealed is the index in Options of the hidden option shown while the
+	// cursor is on it, or -1.
+	revealed int
 
 	KeyMap KeyMap
 
 	selected      int
 	selectedStack stack
 
-	min      int
-	max      int
-	maxStack stack
-	minStack stack
+	// min is the first line of the window of options shown, as numbered by
+	// line, and size the number of lines it holds, which may reach past the
+	// end of the list.
+	min       int
+	size      int
+	sizeStack stack
+	minStack  stack
+
+	// menus holds the levels above the current submenu.
+	menus []menu
+
+	// SlideDuration is how long the options take to slide in when entering
+	// and leaving submenus. Zero shows them right away.
+	SlideDuration time.Duration
+	slide         *slide
+
+	// Height is the number of lines the options and the lines below them
+	// may take up. Zero or less shows all the options. AutoHeight sets it
+	// on each tea.WindowSizeMsg to fit the window, to at least one line,
+	// and keeps it fitted as lines around the options come and go.
+	// Set directly, it takes effect on the next Update or View.
+	// windowHeight is the Height the window was last sized for.
+	Height       int
+	AutoHeight   bool
+	windowHeight int
+
+	// HeightMargin is the number of lines of the window left free below
+	// the picker when AutoHeight sizes it, for example for a help view.
+	HeightMargin int
+	termHeight   int
+
+	// ManagedSize lets Update size the picker on each tea.WindowSizeMsg, as
+	// AutoHeight and AutoWidth set. With it unset those messages are
+	// ignored, and the picker is only sized by SetHeight and SetWidth, as
+	// when it's laid out in a pane by its parent. New pickers have it set.
+	ManagedSize bool
+
+	// FillHeight pads the view with blank lines at the end, so that it's
+	// always as tall as with a full window of options: Height lines, plus
+	// the lines around the options that aren't counted in it, such as the
+	// title, column headers and status bar.
+	FillHeight bool
+
+	// SelectionMode sets how options are chosen. In the SelectMany and
+	// SelectRadio modes each option is marked with one of Glyphs.
+	SelectionMode SelectionMode
+	Glyphs        Glyphs
+	checked       checkSet
+
+	// ShowNumbers shows the number of each selectable option before it,
+	// counting the selectable options in Options from one. With
+	// NumberShownOrder the options are numbered in the order they're shown
+	// instead, for example while filtered.
+	ShowNumbers      bool
+	NumberShownOrder bool
+
+	// ScrollBehavior sets how the window scrolls as the cursor moves.
+	ScrollBehavior ScrollBehavior
+
+	// DragCursorOnScroll moves the cursor onto the closest option in the
+	// window when EnsureVisible or ScrollTo scroll it out of view. Otherwise
+	// the cursor stays where it is until it's moved.
+	DragCursorOnScroll bool
+
+	// NotifyWindowChanges has Update send a WindowChangedMsg whenever the
+	// options shown change.
+	NotifyWindowChanges bool
+	window              WindowChangedMsg
+
+	// WrapLongOptions wraps options that don't fit in Width onto more lines
+	// instead of truncating them. It only applies to the vertical layout.
+	WrapLongOptions bool
+
+	// Layout arranges the options in the view.
+	Layout Layout
+
+	// Columns is the number of columns of LayoutGrid. Zero fits as many
+	// columns of the widest option as Width allows.
+	Columns int
+
+	// cellWidth is the width of the widest option of the grid, measured
+	// once cellWidthOK is set until the rows change. The options are
+	// measured in full, so Width leaves it as it is.
+	cellWidth   int
+	cellWidthOK bool
+
+	// ShowScrollbar draws a scrollbar to the right of the options when they
+	// don't all fit in Height.
+	ShowScrollbar bool
 
-	Height     int
-	AutoHeight bool
+	// ShowOverflowHints shows how many options are out of view above and
+	// below the window. The hints take up two lines of Height.
+	ShowOverflowHints bool
 
+	// DescriptionLines is the number of lines below the options given to
+	// the description of the option on the cursor. Longer descriptions are
+	// cut short. Zero doesn't show descriptions. The lines are taken from
+	// Height.
+	DescriptionLines int
+
+	// ShowStatusBar shows the position of the cursor below the options.
+	ShowStatusBar bool
+	// selectableRows are the rows that can hold the cursor, in order, for
+	// the status bar to count, once selectableOK is set until the rows
+	// change.
+	selectableRows []int
+	selectableOK   bool
+
+	// StatusMessageLifetime is how long messages shown with
+	// NewStatusMessage last.
+	StatusMessageLifetime time.Duration
+	statusMessage         string
+	statusSeq             int
+
+	// Paginated shows the options of the vertical layout a page at a time,
+	// with a page indicator below them. The PrevPage and NextPage keys flip between pages. The
+	// indicator takes up one line of Height.
+	Paginated bool
+	Paginator paginator.Model
+
+	// EmptyMessage is shown when there are no options.
+	EmptyMessage string
+
+	// Spinner and LoadingMessage are shown in place of the options while
+	// they're loading.
+	Spinner        spinner.Model
+	LoadingMessage string
+	loading        bool
+	loadSeq        int
+	loadErr        error
+
+	// Retry sets how a failed load of LoadOptions is retried. Meanwhile
+	// RetryingMessage is shown in place of LoadingMessage, its %s verb
+	// replaced by the time left until the next attempt and its two %d verbs
+	// by the number of that attempt and of Retry.Attempts. Once the load
+	// gives up, the Retry key starts it over.
+	Retry           RetryPolicy
+	RetryingMessage string
+	load            func(context.Context) ([]Option, error)
+	loadCtx         context.Context
+	attempt         int
+	retryAt         time.Time
+
+	// err is shown in a banner above the options, set by an errorMsg.
+	err error
+
+	// result is the last selection, if submitted, or else whether the
+	// picker was cancelled.
+	result    Selection
+	submitted bool
+	cancelled bool
+
+	// QuitOnSelect makes Update return tea.Quit when an option is selected,
+	// and QuitOnCancel when the picker is cancelled, for a program that does
+	// nothing else. Read how it was left with Result after it exits.
+	QuitOnSelect bool
+	QuitOnCancel bool
+
+	// AutoSelect is the index in Options of the option selected on its own
+	// once AutoSelectAfter has passed since Init, unless a key is pressed
+	// before. Meanwhile AutoSelectMessage is shown below the options, its
+	// two %s verbs replaced by the label of the option and the time left.
+	// Zero AutoSelectAfter doesn't select any.
+	AutoSelect        int
+	AutoSelectAfter   time.Duration
+	AutoSelectMessage string
+	autoSelectElapsed time.Duration
+	autoSelectSeq     int
+	autoSelectStopped bool
+
+	// StreamingMessage is shown in place of the status bar while options
+	// are streamed in by StreamOptions. Its %s verb is replaced by the
+	// number of options loaded so far.
+	StreamingMessage string
+	streaming        bool
+
+	// Lookahead is the number of rows above and below the window fetched
+	// from the Provider set with SetProvider before they're scrolled to.
+	// Zero means the height of the window.
+	Lookahead   int
+	provider    Provider
+	provided    [][]Option
+	requested   []bool
+	providerSeq int
+
+	// Width is the number of cells each row may take up. Longer options are
+	// truncated and end with Ellipsis. Zero means unbounded. AutoWidth sets
+	// it to the width of the window on each tea.WindowSizeMsg.
+	// windowWidth is the Width the window last followed the cursor at.
+	Width       int
+	AutoWidth   bool
+	Ellipsis    string
+	windowWidth int
+
+	// LineBreak replaces the line breaks in the labels of the options, so
+	// that each is shown on a row of its own. It defaults to a space; "␤"
+	// shows where they were. TabWidth is the number of spaces each tab in
+	// a label is shown as, 4 by default, since terminals line tabs up with
+	// stops that the width of the rows can't account for.
+	LineBreak string
+	TabWidth  int
+
+	// Cursor is shown before the option it's on, and the other rows are
+	// indented by its width. Change it with SetCursor, which lays the rows
+	// out again for the new width.
 	Cursor string
 	Styles Styles
+
+	// CursorBlink blinks the cursor every CursorBlinkInterval while the
+	// picker is focused. Set it before Init, or start it with Focus.
+	CursorBlink         bool
+	CursorBlinkInterval time.Duration
+	cursorHidden        bool
+	blinkSeq            int
+	blurred             bool
+
+	// DimBlurred draws the rows with Styles.Blurred while the picker is
+	// blurred.
+	DimBlurred bool
+
+	// Marquee scrolls the label on the cursor when it's too long for the
+	// row, by MarqueeStep cells every MarqueeInterval and resting at either
+	// end, instead of truncating it. The other labels are still truncated.
+	// Set it before Init.
+	Marquee         bool
+	MarqueeInterval time.Duration
+	MarqueeStep     int
+	marqueeOption   int
+	marqueeOffset   int
+	marqueeWait     int
+	marqueeSeq      int
+
+	// Indent is the number of spaces the options and the lines below them
+	// are indented by. It's taken from Width.
+	Indent int
+
+	// Bordered draws Styles.Border around the picker, with the title set in
+	// its top edge. Width and AutoHeight then size the picker with its
+	// border.
+	Bordered bool
+
+	// HideCursorGutter leaves out the cursor and the space it takes up, so
+	// that the rows start at the left edge, with their checkboxes, numbers
+	// and icons first. The rows off the cursor drop their padding too, to
+	// stay aligned, and the picker stays focused: the row on the cursor is
+	// told apart by Styles.Selected alone. The cursor is still drawn when
+	// the renderer can't show colors, since nothing else would mark it.
+	HideCursorGutter bool
+
+	// PlainSelected, when set, transforms the label of the option on the
+	// cursor when the renderer can't show colors, so that it stands out
+	// without them, for example by wrapping it in brackets or upper-casing
+	// it. The cursor is then always drawn, and checkboxes in ASCII.
+	PlainSelected func(label string) string
+	renderer      *lipgloss.Renderer
+
+	// Accessible renders the picker for screen readers and braille
+	// displays, as plain text without colors or other escape sequences. The
+	// state of each row is spelled out instead: the row on the cursor is
+	// marked "(selected)", disabled rows "(unavailable)", and the marks of
+	// the SelectMany and SelectRadio modes are the ASCII ones, such as "[x]"
+	// when checked. Updates also describe what they changed in
+	// Announcement. It's set by default when the ACCESSIBLE environment
+	// variable isn't empty.
+	Accessible   bool
+	announcement string
+
+	// zonePrefix and markZone are set by EnableZones.
+	zonePrefix string
+	markZone   func(id, s string) string
+
+	// FilterInput is the text input used to type the filter.
+	FilterInput textinput.Model
+	filterState FilterState
+	filterMode  FilterMode
+	filtered    []Rank
+
+	// filterQuery is the filter value that filtered was computed for. It
+	// lags behind the input while a debounced filter pass is pending.
+	filterQuery   string
+	filterPending bool
+	filterSeq     int
+	filterErr     error
+	filterRegex   compiledFilter
+
+	// filterTotal is the number of options the filter searched through.
+	filterTotal int
+
+	// LiveFilter keeps the filter input open above the options, filtering
+	// as the user types while the remaining keys control the list. Typed
+	// runes always go to the filter, so bindings on letters, like the
+	// default j and k, are shadowed and the arrow keys navigate instead.
+	// The input takes up one line of Height.
+	LiveFilter bool
+
+	// FilterDebounce delays filtering until the filter value hasn't changed
+	// for this long. Zero filters on every keystroke.
+	FilterDebounce time.Duration
+
+	// Filter is used to filter the options. When nil, the built-in filter
+	// for the filter mode is used.
+	Filter FilterFunc
+
+	// FilterAsync, when set, delegates filtering, for example to a
+	// database. It is called with the filter value and returns a command
+	// producing a FilterResultsMsg. Results for a stale value are dropped.
+	FilterAsync func(query string) tea.Cmd
+
+	// FilterFields selects what the filter searches. Zero means the labels
+	// only.
+	FilterFields FilterFields
+
+	// FilterKeepOrder shows filtered options in their original order rather
+	// than best matches first.
+	FilterKeepOrder bool
+
+	// FilterIncludesDisabled keeps disabled options in the filter results.
+	// They are still shown greyed out and can't be selected.
+	FilterIncludesDisabled bool
+
+	// ClearFilterOnSelect clears the filter once an option is selected,
+	// showing the selected option among all the others.
+	ClearFilterOnSelect bool
+
+	// FilterNormalize ignores diacritics when filtering, so that "sao"
+	// matches "São Paulo". It makes filtering slower on large lists, except
+	// in the modes FilterIndex applies to.
+	FilterNormalize bool
+
+	// FilterIndex keeps the labels of the options folded to a single case,
+	// and normalized with FilterNormalize, from one search to the next, for
+	// the substring and prefix filter modes to search large lists faster.
+	// It's built on the first search and kept up to date with the options
+	// as they change after that. It's set by default; unset it to fold the
+	// labels on each search instead of holding a second copy of them.
+	FilterIndex bool
+	filterIndex *filterIndex
+
+	// Format, when set, produces the text rendered for each option from its
+	// index, the total number of options and the option itself.
+	Format FormatFunc
+
+	// CacheView reuses the last view rendered until the model changes,
+	// which saves work when View is called more often than the picker
+	// changes, for example in programs that animate. The rows off the
+	// cursor are reused too while only the cursor moves, so that a frame
+	// renders the row it's on alone. Changes made to the fields of the
+	// model directly must be followed by Invalidate.
+	CacheView bool
+	cache     *viewCache
+	gen       int
+	rowGen    int
+
+	// RenderRow, when set, renders each row in place of the picker, which
+	// still scrolls the rows and moves the cursor. A row should take up a
+	// single line unless WrapLongOptions is set.
+	RenderRow RenderRowFunc
+
+	// SecondaryText, when set, returns text shown right-aligned after the
+	// option at index i of Options, such as a shortcut or a size. It gets up
+	// to SecondaryMaxWidth cells, zero meaning as many as it needs, and the
+	// label is truncated to fit next to it. When the row is too narrow for
+	// both, only the label is shown.
+	SecondaryText     func(i int) string
+	SecondaryMaxWidth int
+
+	// TableColumns lays the options of the vertical layout out as a table,
+	// below a header row holding the titles of the columns. Each option
+	// shows its Cells, cut to the widths of their columns. The header row
+	// takes up one line of Height.
+	TableColumns []Column
+
+	// OnEvent, when set, is called with the events the messages Update
+	// handles cause, for programs that don't run the picker in a
+	// tea.Program to follow it.
+	OnEvent func(Event)
 }
 
-type stack struct {
-	Push   func(int)
-	Pop    func() int
-	Length func() int
+// FormatFunc returns the text to render for the option at index i out of
+// total options. Line breaks in the result are rendered as spaces so that
+// each option keeps to a single row.
+type FormatFunc func(i, total int, value string) string
+
+// RenderRowFunc renders the option at index i of Options, with the cursor on
+// it when selected is true, in at most width cells. Zero means unbounded.
+type RenderRowFunc func(m Model, i int, option string, selected bool, width int) string
+
+// stack is a stack of ints. Its backing array is shared between copies of
+// the model, so Push never appends to it in place: copies of a stack are
+// independent of each other.
+type stack []int
+
+// Push pushes i onto the stack.
+func (s *stack) Push(i int) {
+	*s = append((*s)[:len(*s):len(*s)], i)
 }
 
-func newStack() stack {
-	slice := make([]int, 0)
-	return stack{
-		Push: func(i int) {
-			slice = append(slice, i)
-		},
-		Pop: func() int {
-			res := slice[len(slice)-1]
-			slice = slice[:len(slice)-1]
-			return res
-		},
-		Length: func() int {
-			return len(slice)
-		},
+// Pop removes the top of the stack and returns it, or returns false if the
+// stack is empty.
+func (s *stack) Pop() (int, bool) {
+	if len(*s) == 0 {
+		return 0, false
 	}
+	res := (*s)[len(*s)-1]
+	*s = (*s)[:len(*s)-1]
+	return res, true
 }
 
-func (m Model) pushView() {
+// pushView saves the cursor and window, for popView to restore them.
+func (m *Model) pushView() {
 	m.minStack.Push(m.min)
-	m.maxStack.Push(m.max)
+	m.sizeStack.Push(m.size)
 	m.selectedStack.Push(m.selected)
 }
 
-func (m Model) popView() (int, int, int) {
-	return m.selectedStack.Pop(), m.minStack.Pop(), m.maxStack.Pop()
+// popView restores the cursor and window last saved by pushView, if any.
+func (m *Model) popView() {
+	selected, ok := m.selectedStack.Pop()
+	lo, _ := m.minStack.Pop()
+	size, _ := m.sizeStack.Pop()
+	if ok {
+		m.selected, m.min, m.size = selected, lo, size
+	}
 }
 
-// Init initializes the file picker model.
+// Init initializes the file picker model. It starts the spinner if the
+// options are loading, blinks the cursor if CursorBlink is set and scrolls
+// the label on it if Marquee is.
 func (m Model) Init() tea.Cmd {
-	return nil
+	var cmds []tea.Cmd
+	if m.loading {
+		cmds = append(cmds, m.Spinner.Tick)
+	}
+	if m.CursorBlink && !m.blurred {
+		cmds = append(cmds, m.blinkTick())
+	}
+	if m.Marquee {
+		cmds = append(cmds, m.marqueeTick())
+	}
+	if m.autoSelecting() {
+		cmds = append(cmds, m.autoSelectTick())
+	}
+	return tea.Batch(cmds...)
 }
 
 // Update handles user interactions within the file picker model.
 func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	cmd := m.UpdateInPlace(msg)
+	return m, cmd
+}
+
+// UpdateInPlace is Update for a model held by pointer, which it updates in
+// place rather than returning an updated copy, saving the copies of the
+// model on each message.
+func (m *Model) UpdateInPlace(msg tea.Msg) tea.Cmd {
+	stale := m.clampedSelected() == -1
+	// The grid is measured first, for fitting the window to it.
+	m.syncGrid()
+	m.syncHeight()
+	m.syncWidth()
+	m.repair()
+	m.syncStatusBar()
+	if m.Accessible {
+		defer m.announce(m.announced())
+	}
+	var cmd tea.Cmd
+	switch {
+	case stale && m.selectKey(msg):
+		// The row the key was pressed on is gone, as when Options is shrunk
+		// directly, so nothing is selected, as DidSelectOption reports.
+	case m.OnEvent != nil:
+		cmd = m.updateWithEvents(msg)
+	default:
+		cmd = m.update(msg)
+	}
+	m.syncHeight()
+	m.syncWidth()
+	// Fetch the rows the window has come near.
+	if fetch := m.prefetch(); fetch != nil {
+		cmd = tea.Batch(cmd, fetch)
+	}
+	if m.NotifyWindowChanges {
+		return tea.Batch(cmd, m.windowChanged())
+	}
+	return cmd
+}
+
+// update updates the model with msg.
+func (m *Model) update(msg tea.Msg) tea.Cmd {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
+		if !m.ManagedSize {
+			return nil
+		}
+		m.invalidateSize()
+		m.termHeight = msg.Height
 		if m.AutoHeight {
-			m.Height = msg.Height - marginBottom
+			m.Height = m.autoHeight()
 		}
-		m.max = m.Height - 1
+		if m.AutoWidth {
+			m.Width = msg.Width
+		}
+		// Keep the cursor in a window the new size shrinks.
+		m.fitWindow()
+	case filterDebounceMsg:
+		m.Invalidate()
+		return m.handleFilterDebounce(msg)
+	case FilterResultsMsg:
+		m.Invalidate()
+		m.handleFilterResults(msg)
+	case errorMsg:
+		m.Invalidate()
+		m.handleError(msg)
+	case OptionsLoadedMsg:
+		m.Invalidate()
+		return m.handleOptionsLoaded(msg)
+	case retryTickMsg:
+		m.Invalidate()
+		return m.handleRetryTick(msg)
+	case OptionsBatchMsg:
+		m.Invalidate()
+		return m.handleOptionsBatch(msg)
+	case rowsFetchedMsg:
+		m.Invalidate()
+		m.handleRowsFetched(msg)
+	case statusMessageTimeoutMsg:
+		m.Invalidate()
+		m.handleStatusMessageTimeout(msg)
+	case cursorBlinkMsg:
+		m.invalidateCursor()
+		return m.handleCursorBlink(msg)
+	case slideMsg:
+		m.Invalidate()
+		return m.handleSlide(msg)
+	case autoSelectMsg:
+		m.Invalidate()
+		return m.handleAutoSelect(msg)
+	case marqueeMsg:
+		// Most ticks leave the label where it is, so the view is only
+		// invalidated when it moves.
+		return m.handleMarquee(msg)
+	case spinner.TickMsg:
+		// Let the spinner stop once the options have loaded.
+		if !m.loading && !m.streaming {
+			return nil
+		}
+		m.Invalidate()
+		var cmd tea.Cmd
+		m.Spinner, cmd = m.Spinner.Update(msg)
+		return cmd
 	case tea.KeyMsg:
-		switch {
-		case key.Matches(msg, m.KeyMap.Down):
-			m.selected++
-			if m.selected >= len(m.Options) {
-				m.selected = len(m.Options) - 1
-			}
-			if m.selected > m.max {
-				m.min++
-				m.max++
-			}
-		case key.Matches(msg, m.KeyMap.Up):
-			m.selected--
-			if m.selected < 0 {
-				m.selected = 0
-			}
-			if m.selected < m.min {
-				m.min--
-				m.max--
-			}
+		if m.autoSelecting() {
+			m.Invalidate()
+			m.stopAutoSelect()
+		}
+		switch m.keyTarget(msg) {
+		case toNothing:
+			// Keys are meant for whatever has the focus instead.
+			return nil
+		case toFilter:
+			m.Invalidate()
+			return m.handleFiltering(msg)
+		case toLiveFilter:
+			m.Invalidate()
+			return m.handleLiveFilter(msg)
+		}
+		if m.movesCursor(msg) {
+			m.invalidateCursor()
+		} else {
+			m.Invalidate()
+		}
+		cmd := m.handleBrowsing(msg)
+		m.unreveal()
+		return cmd
+	default:
+		if m.filterState == Filtering {
+			m.Invalidate()
+			return m.handleFiltering(msg)
+		}
+		if m.LiveFilter {
+			m.Invalidate()
+			var cmd tea.Cmd
+			m.FilterInput, cmd = m.FilterInput.Update(msg)
+			return cmd
+		}
+	}
+	return nil
+}
+
+// movesCursor returns whether msg is a key handleBrowsing only moves the
+// cursor or turns the page with.
+func (m Model) movesCursor(msg tea.KeyMsg) bool {
+	switch {
+	case key.Matches(msg, m.KeyMap.Down, m.KeyMap.Up):
+		return true
+	case (m.Layout == LayoutHorizontal || m.Layout == LayoutGrid) && key.Matches(msg, m.KeyMap.Left, m.KeyMap.Right):
+		return true
+	}
+	return m.Paginated && key.Matches(msg, m.KeyMap.NextPage, m.KeyMap.PrevPage)
+}
+
+// handleBrowsing handles key presses while the user is navigating the
+// options.
+func (m *Model) handleBrowsing(msg tea.KeyMsg) tea.Cmd {
+	defer m.resetMarquee(m.optionIndex(m.cursorIndex()))
+	if m.err != nil {
+		if key.Matches(msg, m.KeyMap.DismissError) {
+			m.err = nil
+			return nil
+		}
+		// The error is cleared by the next key acted on.
+		if m.KeyMap.bound(msg.String()) || m.shortcutRow(msg) != -1 {
+			m.err = nil
+		}
+	}
+	switch {
+	case key.Matches(msg, m.KeyMap.Down), m.Layout == LayoutHorizontal && key.Matches(msg, m.KeyMap.Right):
+		m.cursorDown()
+	case key.Matches(msg, m.KeyMap.Up), m.Layout == LayoutHorizontal && key.Matches(msg, m.KeyMap.Left):
+		m.cursorUp()
+	case m.Layout == LayoutGrid && key.Matches(msg, m.KeyMap.Right):
+		m.moveColumn(1)
+	case m.Layout == LayoutGrid && key.Matches(msg, m.KeyMap.Left):
+		m.moveColumn(-1)
+	case m.Paginated && key.Matches(msg, m.KeyMap.NextPage):
+		m.flipPage(1)
+	case m.Paginated && key.Matches(msg, m.KeyMap.PrevPage):
+		m.flipPage(-1)
+	case key.Matches(msg, m.KeyMap.Filter):
+		return m.startFiltering()
+	// Clearing the filter is matched before going back because, by default,
+	// they're both mapped to escape.
+	case m.filterState == FilterApplied && key.Matches(msg, m.KeyMap.ClearFilter):
+		m.resetFilter()
+	case key.Matches(msg, m.KeyMap.Expand):
+		m.expand(true)
+	case key.Matches(msg, m.KeyMap.Collapse):
+		m.expand(false)
+	case key.Matches(msg, m.KeyMap.ToggleGroup):
+		m.toggleGroup()
+	case m.canRetry() && key.Matches(msg, m.KeyMap.Retry):
+		return m.RetryLoad()
+	case key.Matches(msg, m.KeyMap.Back):
+		from := m.listView()
+		if m.PopMenu() {
+			return m.startSlide(from, true)
+		}
+		return m.finish(nil)
+	case m.SelectionMode != SelectOne && key.Matches(msg, m.KeyMap.Toggle):
+		m.toggleChecked()
+	case key.Matches(msg, m.KeyMap.Select):
+		return m.choose()
+	case m.shortcutRow(msg) != -1:
+		m.selected = m.shortcutRow(msg)
+		m.followCursor()
+		return m.choose()
+	}
+	return nil
+}
+
+// CursorDown moves the cursor to the next selectable option, as the Down key
+// does, and returns the updated model.
+func (m Model) CursorDown() Model {
+	m.cursorDown()
+	return m
+}
+
+// cursorDown moves the cursor like CursorDown, without copying the model.
+func (m *Model) cursorDown() {
+	m.invalidateCursor()
+	cursor := m.cursorIndex()
+	if next := m.nextSelectable(cursor); next != -1 {
+		m.selected = next
+	}
+	m.followCursor()
+	m.resetMarquee(m.optionIndex(cursor))
+}
+
+// CursorUp moves the cursor to the previous selectable option, as the Up key
+// does, and returns the updated model.
+func (m Model) CursorUp() Model {
+	m.cursorUp()
+	return m
+}
+
+// cursorUp moves the cursor like CursorUp, without copying the model.
+func (m *Model) cursorUp() {
+	m.invalidateCursor()
+	cursor := m.cursorIndex()
+	if prev := m.prevSelectable(cursor); prev != -1 {
+		m.selected = prev
+	}
+	m.followCursor()
+	// Reveal informational rows above the first selectable option, unless
+	// wrapped rows among them would push the cursor out of the window.
+	if top := m.min; m.prevSelectable(m.selected) == -1 && m.line(m.selected) < m.size {
+		m.min = 0
+		if !m.inWindow(m.selected) {
+			m.min = top
+		}
+	}
+	m.resetMarquee(m.optionIndex(cursor))
+}
+
+// choose acts on the Select key for the option on the cursor. It returns the
+// command finish returns when the option is selected.
+func (m *Model) choose() tea.Cmd {
+	// Selecting a parent in tree mode toggles its children, and selecting a
+	// header toggles its group.
+	r := m.clampedSelected()
+	i := m.optionIndex(r)
+	if m.SelectionMode == SelectRadio {
+		m.SetChecked(i, true)
+	}
+	var cmd tea.Cmd
+	if m.selects(r) {
+		checked := m.CheckedIndexes()
+		values := make([]string, len(checked))
+		for j, c := range checked {
+			values[j] = m.value(c)
 		}
+		cmd = m.finish(&Selection{
+			Index:         i,
+			Option:        m.Options[i],
+			Value:         m.value(i),
+			Checked:       checked,
+			CheckedValues: values,
+		})
+	}
+	switch {
+	case m.branch(i):
+		m.setExpanded(i, !m.nodes[i].expanded)
+	case i != -1 && m.item(i).Kind == Header:
+		m.toggleGroup()
+	case i != -1 && m.ClearFilterOnSelect:
+		// The cursor stays on the selected option.
+		m.resetFilter()
+	}
+	return cmd
+}
+
+// followCursor scrolls the window so that the selected option is visible.
+func (m *Model) followCursor() {
+	if m.Paginated {
+		m.alignPage()
+		return
 	}
-	return m, nil
+	if m.wrapping() {
+		m.followCursorLines()
+		return
+	}
+	defer m.unpinCursor()
+	line := m.line(m.selected)
+	if m.ScrollBehavior == ScrollCentered {
+		m.centerCursor(line)
+		return
+	}
+	if end := m.min + m.size - 1; line > end {
+		m.min += line - end
+	}
+	if line < m.min {
+		m.min = line
+	}
+	m.clampWindow()
 }
 
-// View returns the view of the file picker.
+// View returns the view of the file picker. It doesn't end with a line break,
+// so that it can be joined with other views.
 func (m Model) View() string {
-	if len(m.Options) == 0 {
-		return m.Styles.EmptyDirectory.String()
+	m.syncGrid()
+	m.syncHeight()
+	m.syncWidth()
+	m.repair()
+	return m.cachedView(m.view)
+}
+
+// view renders the view of the file picker, as plain text when Accessible
+// is set.
+func (m Model) view() string {
+	if m.Accessible {
+		return stripEscapes(m.styledView())
+	}
+	return m.styledView()
+}
+
+// styledView renders the view of the file picker.
+func (m Model) styledView() string {
+	if m.Bordered {
+		return m.borderedView()
+	}
+	var s strings.Builder
+	if m.Title != "" {
+		s.WriteString(m.titleView())
+		s.WriteRune('\n')
+	}
+	if m.filterState != Unfiltered || m.LiveFilter {
+		s.WriteString(m.filterView())
+		s.WriteRune('\n')
+	}
+
+	if m.err != nil {
+		s.WriteString(indent(m.errorView(), m.Indent))
+		s.WriteRune('\n')
+	}
+
+	if m.table() {
+		s.WriteString(indent(m.columnHeaderView(), m.Indent))
+		s.WriteRune('\n')
+	}
+
+	list := m.listView()
+	if m.slide != nil {
+		list = m.slideView(list)
+	}
+	s.WriteString(indent(list, m.Indent))
+	if m.FillHeight {
+		return m.padHeight(s.String())
 	}
+	return s.String()
+}
+
+// padHeight pads view with blank lines up to the height of the view with a
+// full window of options.
+func (m Model) padHeight(view string) string {
+	height := m.Height + m.chromeLines()
+	if n := height - strings.Count(view, "\n") - 1; n > 0 {
+		view += strings.Repeat("\n", n)
+	}
+	return view
+}
+
+// listView renders the options and the lines below them.
+func (m Model) listView() string {
 	var s strings.Builder
+	if m.loading {
+		s.WriteString(m.fitLine(m.loadingView(), m.rowWidth()))
+		return s.String()
+	}
+	if m.rowCount() == 0 {
+		if m.filterActive() {
+			s.WriteString(m.noMatchesView())
+		} else {
+			s.WriteString(m.emptyView())
+		}
+		return s.String()
+	}
 
-	for i, f := range m.Options {
-		if i < m.min {
+	switch m.Layout {
+	case LayoutHorizontal:
+		s.WriteString(m.horizontalView())
+		return s.String()
+	case LayoutGrid:
+		s.WriteString(m.gridView())
+		return s.String()
+	}
+
+	cursor := m.clampedSelected()
+	width := m.listWidth()
+	lines := make([]string, 0, min(m.size, m.rowCount()))
+	last := m.lastVisible()
+	sticky := m.stickyHeader()
+	for r := max(m.min, 0); r <= last && r < m.rowCount(); r++ {
+		if m.optionIndex(r) == -1 {
 			continue
 		}
-		if i > m.max {
-			break
+		if r == m.min && sticky != -1 {
+			// The header of the group scrolling past covers its first row.
+			lines = append(lines, m.renderRow(sticky, cursor, width))
+			continue
 		}
+		row := m.renderRow(r, cursor, width)
+		if !strings.Contains(row, "\n") {
+			lines = append(lines, row)
+			continue
+		}
+		lines = append(lines, strings.Split(row, "\n")...)
+	}
+	if size := m.size; m.wrapping() && len(lines) > size {
+		// An option taller than the window is cut short.
+		lines = lines[:size]
+	}
+	if m.ShowScrollbar {
+		lines = m.withScrollbar(lines, width)
+	}
+	n := 0
+	for _, line := range lines {
+		n += len(line) + 1
+	}
+	s.Grow(n)
+	if m.ShowOverflowHints && m.min > 0 {
+		s.WriteString(m.fitLine(m.Styles.Overflow.Render("↑ "+strconv.Itoa(m.min)+" more"), m.rowWidth()))
+		s.WriteRune('\n')
+	}
+	for _, line := range lines {
+		s.WriteString(line)
+		s.WriteRune('\n')
+	}
+	if below := m.rowCount() - 1 - last; m.ShowOverflowHints && below > 0 {
+		s.WriteString(m.fitLine(m.Styles.Overflow.Render("↓ "+strconv.Itoa(below)+" more"), m.rowWidth()))
+		s.WriteRune('\n')
+	}
+	if m.DescriptionLines > 0 {
+		s.WriteString(m.descriptionView())
+		s.WriteRune('\n')
+	}
+	if m.Paginated {
+		// The pages are only counted when they're shown, as that takes a
+		// dot per page.
+		if pages := m.paginatorView(); pages != "" {
+			s.WriteString(m.fitLine(pages, m.rowWidth()))
+			s.WriteRune('\n')
+		}
+	}
+	if m.statusMessage != "" {
+		s.WriteString(m.fitLine(m.Styles.StatusMessage.Render(singleLine(m.statusMessage)), m.rowWidth()))
+		s.WriteRune('\n')
+	}
+	if m.autoSelecting() {
+		s.WriteString(m.fitLine(m.autoSelectView(), m.rowWidth()))
+		s.WriteRune('\n')
+	}
+	switch {
+	case m.streaming:
+		s.WriteString(m.fitLine(m.streamingView(), m.rowWidth()))
+		s.WriteRune('\n')
+	case m.ShowStatusBar:
+		s.WriteString(m.statusBarView())
+		s.WriteRune('\n')
+	}
 
-		name := f
+	// Each line ends with a line break, but the view as a whole doesn't.
+	return strings.TrimSuffix(s.String(), "\n")
+}
 
-		if m.selected == i {
-			selected := fmt.Sprintf(" %s", name)
-			s.WriteString(m.Styles.Cursor.Render(m.Cursor) + m.Styles.Selected.Render(selected))
-			s.WriteRune('\n')
-			continue
+// statusBarView renders the position of the cursor among the selectable
+// options, noting how many options there are in all while filtered, cut to
+// the width of the rows.
+func (m Model) statusBarView() string {
+	rows := m.selectableRows
+	if !m.selectableOK {
+		rows = m.findSelectable()
+	}
+	// The rows up to the one the cursor is on, which is none when it's -1.
+	pos := sort.SearchInts(rows, m.cursorIndex()+1)
+	status := fmt.Sprintf("%d/%d", pos, len(rows))
+	if m.filterActive() {
+		status += fmt.Sprintf(" (filtered from %d)", m.filterTotal)
+	}
+	if m.SelectionMode == SelectMany {
+		status += fmt.Sprintf(" · %d checked", m.checkedCount())
+	}
+	return m.zone("status", m.fitLine(m.Styles.StatusBar.Render(status), m.rowWidth()))
+}
+
+// findSelectable returns the rows that can hold the cursor, in order.
+func (m Model) findSelectable() []int {
+	var rows []int
+	for r := 0; r < m.rowCount(); r++ {
+		if m.selectable(r) {
+			rows = append(rows, r)
 		}
+	}
+	return rows
+}
+
+// syncStatusBar finds the rows the status bar counts, for it not to look
+// at every row on each frame.
+func (m *Model) syncStatusBar() {
+	if m.ShowStatusBar && !m.selectableOK {
+		m.selectableRows, m.selectableOK = m.findSelectable(), true
+	}
+}
 
-		style := m.Styles.Option
+// emptyView renders the message shown when there are no options.
+func (m Model) emptyView() string {
+	if m.Styles.EmptyDirectory.Value() != "" {
+		return m.Styles.EmptyDirectory.String()
+	}
+	return m.Styles.Empty.Render(m.EmptyMessage)
+}
 
-		fileName := style.Render(name)
-		s.WriteString(fmt.Sprintf("  %s", fileName))
-		s.WriteRune('\n')
+// descriptionView renders the description of the option on the cursor,
+// wrapped to Width. It always takes up DescriptionLines lines, so that the
+// view keeps its height as the cursor moves.
+func (m Model) descriptionView() string {
+	var desc string
+	if i := m.optionIndex(m.cursorIndex()); i != -1 {
+		desc = singleLine(m.item(i).Description)
+	}
+	width := m.rowWidth() - m.Styles.Description.GetHorizontalFrameSize()
+	if m.Width > 0 && width > 0 {
+		desc = wrap.String(wordwrap.String(desc, width), width)
 	}
+	lines := strings.Split(desc, "\n")
+	if len(lines) > m.DescriptionLines {
+		lines = lines[:m.DescriptionLines]
+		last := strings.TrimRight(lines[len(lines)-1], " ") + m.Ellipsis
+		if m.Width > 0 && width > 0 {
+			last, _ = truncate(last, width, m.Ellipsis)
+		}
+		lines[len(lines)-1] = last
+	}
+	for len(lines) < m.DescriptionLines {
+		lines = append(lines, "")
+	}
+	for i, line := range lines {
+		lines[i] = m.Styles.Description.Render(line)
+	}
+	return strings.Join(lines, "\n")
+}
 
-	return s.String()
+// titleView renders the title, truncated to fit in Width.
+func (m Model) titleView() string {
+	title := m.Title
+	if m.Width > 0 {
+		title, _ = truncate(title, m.Width-m.Styles.Title.GetHorizontalFrameSize(), m.Ellipsis)
+	}
+	return m.zone("title", m.Styles.Title.Render(title))
+}
+
+// renderRow renders row r, truncated to width cells unless width is zero.
+// cursor is the row the cursor is on. When options wrap, the row may take up
+// several lines.
+func (m Model) renderRow(r, cursor, width int) string {
+	i := m.optionIndex(r)
+	if i == -1 {
+		return ""
+	}
+	if m.RenderRow != nil {
+		return m.zone(strconv.Itoa(i), m.RenderRow(m, i, m.Options[i], r == cursor, width))
+	}
+	if r != cursor {
+		// Rows off the cursor don't change as it moves.
+		return m.cachedRow(r, i, width, func() string {
+			return m.zone(strconv.Itoa(i), m.renderOption(r, -1, m.Options[i], width))
+		})
+	}
+	return m.zone(strconv.Itoa(i), m.renderOption(r, cursor, m.Options[i], width))
+}
+
+// DefaultRenderRow renders the option at index i of Options the way the
+// picker does when RenderRow isn't set, labelled with option. It is meant to
+// be wrapped by custom RenderRow functions.
+func DefaultRenderRow(m Model, i int, option string, selected bool, width int) string {
+	r := m.rowOf(i)
+	if r == -1 {
+		return ""
+	}
+	cursor := -1
+	if selected {
+		cursor = r
+	}
+	return m.renderOption(r, cursor, option, width)
+}
+
+// renderOption renders row r like renderRow, labelled with option.
+func (m Model) renderOption(r, cursor int, option string, width int) string {
+	item := m.item(m.optionIndex(r))
+	name, matches, prefix := m.rowText(r, option)
+	prefix += m.accessibleMarks(r, cursor, item)
+	sec, hint, secW := m.trailing(r, width, prefix, name)
+	if width > 0 {
+		if m.wrapping() {
+			return m.renderWrapped(r, cursor, name, matches, prefix, width)
+		}
+		avail := width - m.leadWidth(r, prefix) - secW
+		if text, ok := m.marqueeText(r, name, avail); ok {
+			// The matches no longer line up with the scrolled label.
+			name, matches = text, nil
+		} else {
+			var kept int
+			name, kept = truncate(name, avail, m.Ellipsis)
+			matches = keepMatches(matches, kept)
+		}
+	}
+	line := m.withTrailing(r, cursor, m.renderLine(r, cursor, item, prefix, name, matches), sec, hint, width)
+	return m.fill(r, cursor, line, width)
+}
+
+// rowText returns the text shown for row r, labelled with option, along
+// with the rune indexes of it matched by the filter and the tree prefix and
+// icon to show before it.
+func (m Model) rowText(r int, option string) (string, []int, string) {
+	i := m.optionIndex(r)
+	item := m.item(i)
+	name := option
+	var matches []int
+	if option == m.Options[i] {
+		matches = m.rowMatches(r)
+	}
+	name, matches = m.labelText(name, matches)
+	if m.Format != nil && (m.selectable(r) || item.Disabled) {
+		// The formatted text no longer lines up with the filter matches.
+		name = singleLine(m.Format(i, len(m.Options), name))
+		matches = nil
+	}
+	if m.table() && item.Kind != Header {
+		cells := m.cells(i)
+		if option != m.Options[i] || cells[0] != option {
+			matches = nil
+		}
+		var kept int
+		name, kept = m.cellsText(cells)
+		matches = keepMatches(matches, kept)
+	}
+	if item.Kind == Header {
+		name = m.headerLabel(i, name)
+	}
+	prefix := m.treePrefix(i)
+	if item.Icon != "" {
+		prefix += singleLine(item.Icon) + " "
+	}
+	return name, matches, prefix
+}
+
+// listWidth returns the width of the rows of the vertical layout, which
+// leaves room for the scrollbar.
+func (m Model) listWidth() int {
+	width := m.rowWidth()
+	if m.ShowScrollbar && width > 0 {
+		// Keep the column of the scrollbar free whether or not it's drawn.
+		width--
+	}
+	return width
+}
+
+// gutterWidth returns the width of the space before the options, which holds
+// the cursor on the row it is on.
+func (m Model) gutterWidth() int {
+	if m.hideCursorGutter() {
+		return 0
+	}
+	return stringWidth(m.Cursor) + 1
+}
+
+// leadWidth returns the width of everything shown before the label of row r,
+// given its tree prefix.
+func (m Model) leadWidth(r int, prefix string) int {
+	return m.gutterWidth() + stringWidth(m.decoration(r, -1)) + stringWidth(prefix)
+}
+
+// blankGutter returns the space before the rows the cursor isn't on, which
+// is as wide as the cursor so that all rows line up.
+func (m Model) blankGutter() string {
+	return strings.Repeat(" ", m.gutterWidth())
+}
+
+// renderLine renders name as the first line of row r.
+func (m Model) renderLine(r, cursor int, item Option, prefix, name string, matches []int) string {
+	if cursor == r {
+		name, matches = m.plainSelected(name, matches)
+		selected := m.rowStyle(r, cursor)
+		cur := withBackground(m.styleFor(r, m.cursorStyle()), selected)
+		glyph := m.cursorView()
+		var head, space string
+		if !m.hideCursorGutter() {
+			head, space = cur.Render(glyph), " "
+		}
+		// The gap after the cursor is left unstyled, so that an underline
+		// or background of Selected starts at the label.
+		if deco := m.decoration(r, cursor); deco != "" {
+			head += space + deco
+			space = ""
+		}
+		head += space
+		if prefix+name == "" {
+			return head
+		}
+		if len(matches) == 0 {
+			return head + selected.Render(prefix+name)
+		}
+		if prefix == "" {
+			return head + m.labelView(r, cursor, name, matches)
+		}
+		return head + selected.Render(prefix) + m.labelView(r, cursor, name, matches)
+	}
+	return m.lead(r, m.decoration(r, cursor), prefix) + m.labelView(r, cursor, name, matches)
+}
+
+// singleLine replaces line breaks in s with spaces.
+func singleLine(s string) string {
+	return lineBreakReplacer.Replace(s)
 }
 
-// DidSelectFile returns whether a user has selected a file (on this msg).
+var lineBreakReplacer = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ")
+
+// labelText returns the label s as it's shown, with its line breaks replaced
+// by LineBreak and its tabs by TabWidth spaces, along with the rune indexes
+// matched by the filter moved to where those runes ended up. Matches of runes
+// replaced by nothing are dropped.
+func (m Model) labelText(s string, matches []int) (string, []int) {
+	if !strings.ContainsAny(s, "\r\n\t") {
+		return s, matches
+	}
+	var (
+		lb    = []rune(singleLine(m.LineBreak))
+		tab   = []rune(strings.Repeat(" ", max(m.TabWidth, 0)))
+		runes = []rune(s)
+		text  = make([]rune, 0, len(runes))
+		// at holds the index in text of each rune of s.
+		at = make([]int, len(runes)+1)
+	)
+	for j := 0; j < len(runes); j++ {
+		at[j] = len(text)
+		switch r := runes[j]; {
+		case r == '\r' && j+1 < len(runes) && runes[j+1] == '\n':
+			// A CRLF is a single line break.
+			j++
+			at[j] = len(text)
+			text = append(text, lb...)
+		case r == '\r', r == '\n':
+			text = append(text, lb...)
+		case r == '\t':
+			text = append(text, tab...)
+		default:
+			text = append(text, r)
+		}
+	}
+	at[len(runes)] = len(text)
+
+	var moved []int
+	for _, j := range matches {
+		if j < len(runes) && at[j+1] > at[j] {
+			moved = append(moved, at[j])
+		}
+	}
+	return string(text), moved
+}
+
+// truncate shortens s to fit in width cells, ending it with tail when it is
+// cut. Escape sequences in s are kept and take up no room. When a wide rune
+// doesn't fit, the cut is padded with spaces so that the result is exactly
+// width cells wide. Grapheme clusters are never split. It also returns how
+// many of the runes of s were kept.
+func truncate(s string, width int, tail string) (string, int) {
+	if stringWidth(s) <= width {
+		return s, utf8.RuneCountInString(s)
+	}
+	runes := []rune(s)
+	width -= stringWidth(tail)
+	if width < 0 {
+		return "", 0
+	}
+
+	var (
+		b      strings.Builder
+		n, w   int
+		escape bool
+		styled bool
+		widths = runeWidths(runes)
+	)
+loop:
+	for ; n < len(runes); n++ {
+		r := runes[n]
+		switch {
+		case r == ansi.Marker:
+			escape, styled = true, true
+		case escape:
+			escape = !ansi.IsTerminator(r)
+		default:
+			rw := widths[n]
+			if w+rw > width {
+				b.WriteString(strings.Repeat(" ", width-w))
+				break loop
+			}
+			w += rw
+		}
+		b.WriteRune(r)
+	}
+	if styled {
+		// Don't let the styles of s run into the tail.
+		b.WriteString("\x1b[0m")
+	}
+	b.WriteString(tail)
+	return b.String(), n
+}
+
+// keepMatches drops the matched rune indexes that are past the first n runes.
+func keepMatches(matches []int, n int) []int {
+	kept := matches[:0:0]
+	for _, j := range matches {
+		if j < n {
+			kept = append(kept, j)
+		}
+	}
+	return kept
+}
+
+// rowWidth returns the number of cells each row may take up once indented,
+// or zero when the width is unbounded.
+func (m Model) rowWidth() int {
+	width := m.contentWidth()
+	if width <= 0 {
+		return 0
+	}
+	return max(width-max(m.Indent, 0), 1)
+}
+
+// indent indents each line of s by n spaces.
+func indent(s string, n int) string {
+	if n <= 0 {
+		return s
+	}
+	pad := strings.Repeat(" ", n)
+	return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+}
+
+// allLines is the size of a window in which all the options fit.
+const allLines = math.MaxInt32
+
+// sizeWindow sizes the window of options shown to fit in Height, less the
+// lines taken up below them, but at least one line, from the first line it
+// shows. With no Height, all the options are shown.
+func (m *Model) sizeWindow() {
+	m.windowHeight = m.Height
+	if m.Height <= 0 {
+		m.min, m.size = 0, allLines
+		return
+	}
+	size := m.Height
+	if m.LiveFilter {
+		size--
+	}
+	if m.ShowOverflowHints {
+		size -= 2
+	}
+	if m.DescriptionLines > 0 {
+		size -= m.DescriptionLines
+	}
+	if m.Paginated {
+		m.size = size - 1
+		m.alignPage()
+		return
+	}
+	// The window stays scrolled to where it starts.
+	m.size = max(size, 1)
+}
+
+// SetHeight sets the number of lines the options and the lines below them
+// may take up, in place of sizing them to the window, and sizes the window of
+// options shown to fit, keeping the cursor in it. Zero or less shows all the
+// options.
+func (m *Model) SetHeight(h int) {
+	m.Invalidate()
+	m.AutoHeight = false
+	m.Height = max(h, 0)
+	m.fitWindow()
+}
+
+// fitWindow sizes the window of options shown for Height, scrolled where it
+// was and keeping the cursor in it.
+func (m *Model) fitWindow() {
+	// The cursor may be on a row past selected, when that one can't be
+	// selected, and it's that row the window has to hold, or a page show.
+	if r := m.cursorIndex(); r != -1 {
+		m.selected = r
+	}
+	if m.Height <= 0 || m.Paginated {
+		m.sizeWindow()
+		return
+	}
+
+	// Keep the offset the window is scrolled to, but don't leave it hanging
+	// past the end of the list.
+	m.sizeWindow()
+	m.clampWindow()
+	m.followCursor()
+}
+
+// autoHeight returns the Height that AutoHeight sets: the height of the
+// window less HeightMargin and the lines the picker shows around the
+// options, but at least one line.
+func (m Model) autoHeight() int {
+	return max(m.termHeight-m.HeightMargin-m.chromeLines(), 1)
+}
+
+// chromeLines returns the number of lines shown around the options that the
+// window of options shown doesn't make room for itself.
+func (m Model) chromeLines() int {
+	n := 0
+	if m.Bordered {
+		n += m.Styles.Border.GetVerticalFrameSize()
+	} else if m.Title != "" {
+		n++
+	}
+	if m.filterState != Unfiltered && !m.LiveFilter {
+		n++
+	}
+	if m.filterErr != nil && (m.filterState != Unfiltered || m.LiveFilter) {
+		// The error of an invalid expression is shown below the filter.
+		n++
+	}
+	if m.err != nil {
+		n++
+	}
+	if m.table() {
+		n++
+	}
+	if m.statusMessage != "" {
+		n++
+	}
+	if m.autoSelecting() {
+		n++
+	}
+	if m.ShowStatusBar || m.streaming {
+		n++
+	}
+	return n
+}
+
+// syncHeight sizes the window for Height if it has been set directly since
+// the window was last sized, or if AutoHeight sizes it for lines shown
+// around the options that have come or gone since.
+func (m *Model) syncHeight() {
+	if m.AutoHeight && m.termHeight > 0 {
+		m.Height = m.autoHeight()
+	}
+	if m.Height != m.windowHeight {
+		m.fitWindow()
+	}
+}
+
+// syncWidth scrolls the window back to the cursor if Width has changed since
+// it last followed it, as options wrapped anew may have pushed it out.
+func (m *Model) syncWidth() {
+	if m.Width != m.windowWidth {
+		m.windowWidth = m.Width
+		m.ensureCursorVisible()
+	}
+}
+
+// repair moves the cursor and window back onto the rows there are, should
+// Options have been assigned fewer options than the cursor or window was on,
+// dropping the rows and filter results of the options gone, and resizes a
+// window left empty, starting past its end.
+func (m *Model) repair() {
+	m.dropStaleRows()
+	n := m.rowCount()
+	if m.selected >= 0 && m.selected < n && m.min >= 0 && m.size > 0 && m.min < max(m.lineCount(), 1) {
+		return
+	}
+	m.selected = max(min(m.selected, n-1), 0)
+	if r := m.cursorIndex(); r != -1 {
+		m.selected = r
+	}
+	m.min = max(m.min, 0)
+	m.fitWindow()
+}
+
+// SetCursor sets the cursor shown before the option it's on, and lays the
+// rows out again for its width, which the rows off the cursor are indented
+// by.
+func (m *Model) SetCursor(s string) {
+	m.Invalidate()
+	m.Cursor = singleLine(s)
+	m.relayout()
+}
+
+// SetWidth sets the number of cells each row may take up. Zero means
+// unbounded.
+func (m *Model) SetWidth(w int) {
+	m.Invalidate()
+	m.Width = w
+	m.syncWidth()
+}
+
+// DidSelectOption returns whether a user has selected an option (on this msg)
+// and, if so, its label.
 func (m Model) DidSelectOption(msg tea.Msg) (bool, string) {
-	didSelect, option := m.didSelectOption(msg)
+	didSelect, i := m.didSelectIndex(msg)
 	if didSelect {
-		return true, option
+		return true, m.Options[i]
 	}
 	return false, ""
 }
 
-func (m Model) didSelectOption(msg tea.Msg) (bool, string) {
-	if len(m.Options) == 0 {
-		return false, ""
+// DidSelectIndex returns whether a user has selected an option (on this msg)
+// and, if so, its index in Options. The index refers to Options even while a
+// filter is narrowing the options shown.
+func (m Model) DidSelectIndex(msg tea.Msg) (bool, int) {
+	return m.didSelectIndex(msg)
+}
+
+// ID returns the identifier of the picker. The messages it sends carry it,
+// and it ignores those carrying another, so that pickers in the same program
+// don't react to each other's messages.
+func (m Model) ID() int {
+	return m.id
+}
+
+// Index returns the index in Options of the option under the cursor, or -1
+// if there is none.
+func (m Model) Index() int {
+	return m.optionIndex(m.cursorIndex())
+}
+
+// SetSelected moves the cursor onto the option at index i of Options and
+// scrolls it into view. It does nothing if the option can't be selected or
+// is hidden, by the filter or in a collapsed group.
+func (m *Model) SetSelected(i int) {
+	m.Invalidate()
+	r := m.rowOf(i)
+	if r == -1 || !m.selectable(r) {
+		return
 	}
-	switch msg := msg.(type) {
-	case tea.KeyMsg:
-		// If the msg does not match the Select keymap then this could not have been a selection.
-		if !key.Matches(msg, m.KeyMap.Select) {
-			return false, ""
-		}
+	from := m.Index()
+	m.selected = r
+	m.followCursor()
+	m.resetMarquee(from)
+	m.unreveal()
+}
 
-		// The key press was a selection, let's confirm whether the current file could
-		// be selected or used for navigating deeper into the stack.
-		f := m.Options[m.selected]
+// Choose acts on the option on the cursor as the Select key does, returning
+// the command the key would.
+func (m *Model) Choose() tea.Cmd {
+	m.Invalidate()
+	return m.choose()
+}
 
-		return true, f
+// selectKey returns whether msg is a press of the Select key for the list.
+func (m Model) selectKey(msg tea.Msg) bool {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	return ok && m.keyTarget(keyMsg) == toList && key.Matches(keyMsg, m.KeyMap.Select)
+}
 
-		// If the msg was not a KeyMsg, then the file could not have been selected this iteration.
-		// Only a KeyMsg can select a file.
-	default:
-		return false, ""
+// didSelectIndex returns whether msg selects an option and, if so, the index
+// of that option in Options.
+func (m Model) didSelectIndex(msg tea.Msg) (bool, int) {
+	if m.autoSelectDue(msg) {
+		return true, m.AutoSelect
 	}
-	return false, ""
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || m.keyTarget(keyMsg) != toList {
+		return false, -1
+	}
+	// Only the Select key or the shortcut of an option selects it.
+	r := m.shortcutRow(keyMsg)
+	if key.Matches(keyMsg, m.KeyMap.Select) {
+		r = m.clampedSelected()
+	}
+	if !m.selects(r) {
+		return false, -1
+	}
+	return true, m.optionIndex(r)
+}
+
+// keyTarget is what a key press is routed to.
+type keyTarget int
+
+const (
+	// toNothing ignores the key, while the picker is blurred.
+	toNothing keyTarget = iota
+
+	// toFilter hands the key to the filter input being edited.
+	toFilter
+
+	// toLiveFilter hands the key to the input of a live filter.
+	toLiveFilter
+
+	// toList hands the key to handleBrowsing, to control the list.
+	toList
+)
+
+// keyTarget returns what msg is routed to. An input taking the keys typed
+// gets them first, so that accepting it doesn't select an option and the
+// letters typed into it don't move the cursor or trigger shortcuts.
+func (m Model) keyTarget(msg tea.KeyMsg) keyTarget {
+	switch {
+	case m.blurred:
+		return toNothing
+	case m.filterState == Filtering:
+		return toFilter
+	case m.LiveFilter && m.liveFilterKey(msg):
+		return toLiveFilter
+	case pasted(msg):
+		// Pasted text is only meant for the filter input, and never taken
+		// for the keys it holds.
+		return toNothing
+	}
+	return toList
+}
+
+// selects returns whether choosing row r selects its option. Informational
+// rows can't be selected, so a list made up only of them behaves like an
+// empty one. Parents in a tree and group headers are toggled instead. Nothing
+// is selected while the spinner is shown in place of the options, nor before
+// the option arrives from a Provider.
+func (m Model) selects(r int) bool {
+	i := m.optionIndex(r)
+	return i != -1 && !m.loading && !m.pending(i) && !m.branch(i) && m.item(i).Kind != Header
 }
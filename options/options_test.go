@@ -0,0 +1,1203 @@
+package options
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// newTestModel returns a picker rendering without colors, for views to be
+// compared as plain text.
+func newTestModel(opts ...Opt) Model {
+	r := lipgloss.NewRenderer(io.Discard)
+	r.SetColorProfile(termenv.Ascii)
+	m := NewWithRenderer(r, opts...)
+	m.Accessible = false
+	return m
+}
+
+// newColorModel returns a picker rendering in 16 colors, for the glyphs and
+// styles the picker leaves out without colors to be drawn.
+func newColorModel(opts ...Opt) Model {
+	r := lipgloss.NewRenderer(io.Discard)
+	r.SetColorProfile(termenv.ANSI)
+	m := NewWithRenderer(r, opts...)
+	m.Accessible = false
+	return m
+}
+
+// numbered returns n options labelled o0, o1 and so on.
+func numbered(n int) []string {
+	options := make([]string, n)
+	for i := range options {
+		options[i] = "o" + strconv.Itoa(i)
+	}
+	return options
+}
+
+// testKeys maps the names used by press to the keys they stand for.
+var testKeys = map[string]tea.KeyType{
+	"up":        tea.KeyUp,
+	"down":      tea.KeyDown,
+	"left":      tea.KeyLeft,
+	"right":     tea.KeyRight,
+	"home":      tea.KeyHome,
+	"end":       tea.KeyEnd,
+	"pgup":      tea.KeyPgUp,
+	"pgdown":    tea.KeyPgDown,
+	"enter":     tea.KeyEnter,
+	"esc":       tea.KeyEsc,
+	"tab":       tea.KeyTab,
+	"backspace": tea.KeyBackspace,
+	" ":         tea.KeySpace,
+	"ctrl+n":    tea.KeyCtrlN,
+	"ctrl+p":    tea.KeyCtrlP,
+	"ctrl+r":    tea.KeyCtrlR,
+	"ctrl+t":    tea.KeyCtrlT,
+}
+
+// keyMsg returns the key press named name, or the runes of name typed as one
+// key press when it isn't a key in testKeys.
+func keyMsg(name string) tea.KeyMsg {
+	if t, ok := testKeys[name]; ok {
+		return tea.KeyMsg{Type: t}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(name)}
+}
+
+// press updates m with the keys named, in order.
+func press(m *Model, names ...string) {
+	for _, name := range names {
+		m.UpdateInPlace(keyMsg(name))
+	}
+}
+
+// times returns name n times, for press to press the key n times over.
+func times(n int, name string) []string {
+	names := make([]string, n)
+	for i := range names {
+		names[i] = name
+	}
+	return names
+}
+
+// resize updates m as a terminal of width by height cells does.
+func resize(m *Model, width, height int) {
+	m.UpdateInPlace(tea.WindowSizeMsg{Width: width, Height: height})
+}
+
+// within fails t if f doesn't return within a second, as when it loops
+// forever.
+func within(t *testing.T, f func()) {
+	t.Helper()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f()
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("didn't return")
+	}
+}
+
+func TestRelayoutHiddenCollapsedHeader(t *testing.T) {
+	m := newTestModel(WithItems([]Option{
+		{Label: "root", Children: []Option{
+			{Label: "G", Kind: Header, Collapsed: true},
+			{Label: "apple"},
+		}},
+		{Label: "other"},
+	}))
+	within(t, func() {
+		m.SetFilterText("apple")
+		press(&m, "esc")
+	})
+	if i := m.Index(); i == -1 || m.Options[i] != "root" {
+		t.Errorf("cursor on option %d, want it on the parent of the hidden header", i)
+	}
+}
+
+func TestChromeFitsNarrowBorder(t *testing.T) {
+	m := newTestModel(WithOptions(numbered(10)))
+	m.Bordered = true
+	m.AutoHeight = true
+	m.ShowStatusBar = true
+	m.ShowOverflowHints = true
+	m.SetFilterMode(FilterModeRegex)
+	resize(&m, 16, 12)
+	press(&m, "/", "o", "(")
+	if m.filterErr == nil {
+		t.Fatal("no error for an invalid expression")
+	}
+
+	lines := strings.Split(m.View(), "\n")
+	if len(lines) > 12 {
+		t.Errorf("view is %d lines tall in a terminal 12 lines high", len(lines))
+	}
+	for _, line := range lines {
+		if w := lipgloss.Width(line); w > 16 {
+			t.Errorf("line %q is %d cells wide in a terminal 16 cells wide", line, w)
+		}
+	}
+}
+
+func TestStatusBarCounts(t *testing.T) {
+	m := newTestModel(WithItems([]Option{
+		{Label: "Fruit", Kind: Header},
+		{Label: "apple"},
+		{Label: "apricot", Disabled: true},
+		{Label: "avocado"},
+		{Label: "Veg", Kind: Header},
+		{Label: "bean"},
+		{Label: "beet"},
+	}))
+	m.ShowStatusBar = true
+	resize(&m, 30, 20)
+	steps := []struct {
+		name   string
+		change func(*Model)
+		want   string
+	}{
+		{"top", func(*Model) {}, "1/4"},
+		{"moved", func(m *Model) { press(m, "down", "down") }, "3/4"},
+		{"collapsed", func(m *Model) { m.SetGroupCollapsed(0, true) }, "1/2"},
+		{"expanded", func(m *Model) { m.SetGroupCollapsed(0, false) }, "3/4"},
+		{"filtered", func(m *Model) { press(m, "/", "b", "enter") }, "1/2 (filtered from 4)"},
+		{"filter cleared", func(m *Model) { press(m, "esc") }, "3/4"},
+		{"headers selectable", func(m *Model) { m.SelectableHeaders = true; m.Invalidate() }, "5/6"},
+		{"other options", func(m *Model) { m.SetOptions(numbered(3)) }, "1/3"},
+	}
+	for _, step := range steps {
+		step.change(&m)
+		// Update counts the rows for the view to show.
+		m.UpdateInPlace(nil)
+		view := m.View()
+		if last := view[strings.LastIndex(view, "\n")+1:]; strings.TrimSpace(last) != step.want {
+			t.Errorf("%s: status bar %q, want %q", step.name, last, step.want)
+		}
+	}
+}
+
+func TestCursorWidthsAlign(t *testing.T) {
+	for _, cursor := range []string{">", "→", "=>", "👉", "界", "👍🏽", "é"} {
+		m := newTestModel(WithOptions([]string{"aa", "bb", "cc"}))
+		m.SetCursor(cursor)
+		resize(&m, 20, 10)
+		press(&m, "down")
+
+		// Graphemes are measured as a whole, as terminals show them.
+		lines := strings.Split(m.View(), "\n")
+		want := stringWidth(cursor) + 1
+		for i, line := range lines {
+			label := m.Options[i]
+			j := strings.Index(line, label)
+			if j == -1 {
+				t.Fatalf("cursor %q: line %q doesn't show %q", cursor, line, label)
+			}
+			if w := stringWidth(line[:j]); w != want {
+				t.Errorf("cursor %q: %q starts at cell %d, want %d", cursor, label, w, want)
+			}
+		}
+	}
+}
+
+func TestFilterStateTransitions(t *testing.T) {
+	tests := []struct {
+		name  string
+		keys  []string
+		reset bool
+		state FilterState
+		value string
+	}{
+		{name: "initial", state: Unfiltered},
+		{name: "start", keys: []string{"/"}, state: Filtering},
+		{name: "type", keys: []string{"/", "a"}, state: Filtering, value: "a"},
+		{name: "apply", keys: []string{"/", "a", "enter"}, state: FilterApplied, value: "a"},
+		{name: "clear applied", keys: []string{"/", "a", "enter", "esc"}, state: Unfiltered},
+		{name: "cancel while typing", keys: []string{"/", "a", "esc"}, state: Unfiltered},
+		{name: "apply empty", keys: []string{"/", "enter"}, state: Unfiltered},
+		{name: "apply erased", keys: []string{"/", "a", "backspace", "enter"}, state: Unfiltered},
+		{name: "edit applied", keys: []string{"/", "a", "enter", "/"}, state: Filtering, value: "a"},
+		{name: "reapply", keys: []string{"/", "a", "enter", "/", "p", "enter"}, state: FilterApplied, value: "ap"},
+		{name: "reset while typing", keys: []string{"/", "a"}, reset: true, state: Unfiltered},
+		{name: "reset applied", keys: []string{"/", "a", "enter"}, reset: true, state: Unfiltered},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(WithOptions([]string{"apple", "banana", "cherry"}))
+			resize(&m, 20, 10)
+			press(&m, tt.keys...)
+			if tt.reset {
+				m.ResetFilter()
+			}
+			if got := m.FilterState(); got != tt.state {
+				t.Errorf("FilterState() = %v, want %v", got, tt.state)
+			}
+			if got := m.FilterValue(); got != tt.value {
+				t.Errorf("FilterValue() = %q, want %q", got, tt.value)
+			}
+			if m.Filtering() != (tt.state == Filtering) {
+				t.Errorf("Filtering() = %t in the %v state", m.Filtering(), tt.state)
+			}
+			if tt.state == Unfiltered && len(m.VisibleOptions()) != 3 {
+				t.Errorf("VisibleOptions() = %q unfiltered, want all the options", m.VisibleOptions())
+			}
+		})
+	}
+}
+
+// cursorLabel returns the label shown on the row the cursor is on.
+func cursorLabel(t *testing.T, m Model) string {
+	t.Helper()
+	for _, line := range strings.Split(m.View(), "\n") {
+		if label, ok := strings.CutPrefix(line, m.Cursor+" "); ok {
+			return strings.TrimSpace(label)
+		}
+	}
+	t.Fatalf("no row on the cursor in:\n%s", m.View())
+	return ""
+}
+
+func TestSelectUnderFilter(t *testing.T) {
+	labels := []string{"red apple", "green pear", "red cherry", "blue plum", "red plum"}
+	items := make([]Option, len(labels))
+	for i, label := range labels {
+		items[i] = Option{Label: label, Value: "v" + strconv.Itoa(i)}
+	}
+	tests := []struct {
+		name string
+		keys []string
+		want string
+	}{
+		// The best matches come first, so rows under the filter aren't in
+		// the order of Options.
+		{"moved", []string{"/", "r", "e", "d", "enter", "down"}, "red apple"},
+		{"not moved while typing", []string{"/", "r", "e", "d", "down"}, "red plum"},
+		{"edited after moving", []string{"/", "r", "e", "d", "enter", "down", "down", "/", " ", "p", "enter"}, "red plum"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(WithItems(items))
+			resize(&m, 30, 10)
+			press(&m, tt.keys...)
+			if m.Filtering() {
+				press(&m, "enter")
+			}
+			shown := cursorLabel(t, m)
+			if shown != tt.want {
+				t.Fatalf("cursor on %q, want %q", shown, tt.want)
+			}
+
+			enter := keyMsg("enter")
+			ok, i := m.DidSelectIndex(enter)
+			if !ok || i < 0 || i >= len(labels) || labels[i] != shown {
+				t.Fatalf("DidSelectIndex = %t, %d with the cursor on %q", ok, i, shown)
+			}
+			if ok, option := m.DidSelectOption(enter); !ok || option != shown {
+				t.Errorf("DidSelectOption = %t, %q, want %q", ok, option, shown)
+			}
+			m.UpdateInPlace(enter)
+			sel, ok := m.Result()
+			if !ok || sel.Index != i || sel.Option != shown || sel.Value != "v"+strconv.Itoa(i) {
+				t.Errorf("Result() = %+v, %t, want option %d, %q, of value v%d", sel, ok, i, shown, i)
+			}
+		})
+	}
+}
+
+func TestGrid(t *testing.T) {
+	m := newTestModel(WithOptions([]string{"one", "two", "three", "four", "five", "six", "a seventh, long label"}))
+	m.Layout = LayoutGrid
+	m.Columns = 3
+	resize(&m, 30, 10)
+
+	steps := []struct {
+		keys   []string
+		height int
+		want   string
+	}{
+		// Options flow down the columns, leaving the last one short, and
+		// each cell is cut to the width of the columns.
+		{nil, 10, "" +
+			"> one       four      a seve…\n" +
+			"  two       five\n" +
+			"  three     six"},
+		// Moving right into the short column lands on its last option.
+		{[]string{"down", "down", "right", "right"}, 10, "" +
+			"  one       four    > a seve…\n" +
+			"  two       five\n" +
+			"  three     six"},
+		{nil, 2, "" +
+			"  one       four    > a seve…\n" +
+			"  two       five"},
+		// The window scrolls by rows of the grid.
+		{[]string{"left", "down", "down"}, 2, "" +
+			"  two       five\n" +
+			"  three   > six"},
+		{[]string{"left", "up", "up"}, 2, "" +
+			"> one       four      a seve…\n" +
+			"  two       five"},
+	}
+	for i, step := range steps {
+		resize(&m, 30, step.height)
+		press(&m, step.keys...)
+		if got := m.View(); got != step.want {
+			t.Errorf("step %d: view is\n%s\nwant\n%s", i, got, step.want)
+		}
+	}
+}
+
+func TestGridFitsColumns(t *testing.T) {
+	m := newTestModel(WithOptions([]string{"a", "b", "c", "d", "e", "f"}))
+	m.Layout = LayoutGrid
+	resize(&m, 30, 10)
+	steps := []struct {
+		name   string
+		change func(*Model)
+		cols   int
+	}{
+		{"short labels", func(*Model) {}, 6},
+		{"longer labels", func(m *Model) { m.SetOptions([]string{"apple", "banana", "cherry", "d", "e", "f"}) }, 3},
+		{"narrower", func(m *Model) { m.SetWidth(20) }, 2},
+		{"filtered to one", func(m *Model) { m.SetFilterText("d") }, 1},
+		{"filter cleared", func(m *Model) { m.SetFilterText("") }, 2},
+		{"longer cursor", func(m *Model) { m.SetCursor("-->") }, 1},
+	}
+	for _, step := range steps {
+		step.change(&m)
+		_ = m.View()
+		if cols, _ := m.gridSize(); cols != step.cols {
+			t.Errorf("%s: %d columns, want %d", step.name, cols, step.cols)
+		}
+	}
+}
+
+func TestViewLineCount(t *testing.T) {
+	tests := []struct {
+		options, height, want int
+	}{
+		{1, 10, 1},
+		{3, 10, 3},
+		{10, 4, 4},
+		{10, 10, 10},
+	}
+	for _, tt := range tests {
+		m := newTestModel(WithOptions(numbered(tt.options)))
+		resize(&m, 20, tt.height)
+		view := m.View()
+		if strings.HasSuffix(view, "\n") {
+			t.Errorf("%d options in %d lines: view ends with a line break", tt.options, tt.height)
+		}
+		if lines := strings.Count(view, "\n") + 1; lines != tt.want {
+			t.Errorf("%d options in %d lines: view has %d lines, want %d", tt.options, tt.height, lines, tt.want)
+		}
+		if got := lipgloss.Height(lipgloss.JoinVertical(lipgloss.Left, view, "below")); got != tt.want+1 {
+			t.Errorf("%d options in %d lines: view joined above a line is %d lines, want %d", tt.options, tt.height, got, tt.want+1)
+		}
+	}
+}
+
+func TestSecondaryTextNarrow(t *testing.T) {
+	m := newTestModel(WithOptions([]string{"short", "a much longer label here"}))
+	m.SecondaryText = func(i int) string { return []string{"12 KB", "annotation text"}[i] }
+	m.SecondaryMaxWidth = 10
+	tests := []struct {
+		width int
+		want  string
+	}{
+		// The secondary text is cut to SecondaryMaxWidth, and the label
+		// to what's left.
+		{40, "" +
+			"> short                            12 KB\n" +
+			"  a much longer label here    annotatio…"},
+		{30, "" +
+			"> short                  12 KB\n" +
+			"  a much longer l…  annotatio…"},
+		// The secondary text makes way once the label would be cut too
+		// short, on each row as it needs to.
+		{16, "" +
+			"> short    12 KB\n" +
+			"  a much longer…"},
+		{12, "" +
+			"> short\n" +
+			"  a much lo…"},
+	}
+	for _, tt := range tests {
+		resize(&m, tt.width, 10)
+		if got := m.View(); got != tt.want {
+			t.Errorf("width %d: view is\n%s\nwant\n%s", tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestScrollCentered(t *testing.T) {
+	for _, tt := range []struct{ options, height int }{{20, 5}, {20, 4}, {5, 5}, {4, 9}, {2, 1}} {
+		m := newTestModel(WithOptions(numbered(tt.options)))
+		m.ScrollBehavior = ScrollCentered
+		resize(&m, 20, tt.height)
+		// The cursor stays on the middle line, or the one above it for an
+		// even number of lines, but the window stays within the list near
+		// its ends.
+		want := func(r int) int {
+			return max(min(r-(tt.height-1)/2, tt.options-tt.height), 0)
+		}
+		check := func(dir string, r int) {
+			t.Helper()
+			if m.selected != r || m.min != want(r) {
+				t.Errorf("%d options in %d lines, moving %s: cursor on %d and window at %d, want %d and %d",
+					tt.options, tt.height, dir, m.selected, m.min, r, want(r))
+			}
+		}
+		check("down", 0)
+		for r := 1; r < tt.options; r++ {
+			press(&m, "down")
+			check("down", r)
+		}
+		for r := tt.options - 2; r >= 0; r-- {
+			press(&m, "up")
+			check("up", r)
+		}
+	}
+}
+
+func TestStackCopies(t *testing.T) {
+	var s stack
+	s.Push(1)
+	c := s
+	c.Push(2)
+	s.Push(3)
+	if top, _ := c.Pop(); top != 2 {
+		t.Errorf("copy popped %d, want the 2 pushed on it", top)
+	}
+	if top, _ := s.Pop(); top != 3 {
+		t.Errorf("original popped %d, want the 3 pushed on it", top)
+	}
+
+	m := newTestModel(WithOptions(numbered(10)))
+	resize(&m, 20, 5)
+	press(&m, "down", "down")
+	c2 := m
+	c2.pushView()
+	if len(m.selectedStack) != 0 || len(m.minStack) != 0 || len(m.sizeStack) != 0 {
+		t.Error("pushing the view of a copy pushed it on the original")
+	}
+	m.pushView()
+	press(&m, "down", "down", "down", "down")
+	m.pushView()
+	c2.popView()
+	if c2.selected != 2 || len(c2.selectedStack) != 0 {
+		t.Errorf("copy popped back to the cursor on %d, want 2, with %d views left", c2.selected, len(c2.selectedStack))
+	}
+	if len(m.selectedStack) != 2 {
+		t.Errorf("popping the view of a copy left %d views on the original, want 2", len(m.selectedStack))
+	}
+}
+
+func TestCursorUpDown(t *testing.T) {
+	items := []Option{
+		{Label: "note", Kind: Info},
+		{Label: "a"},
+		{Label: "b", Disabled: true},
+		{Label: "G", Kind: Header},
+		{Label: "c"},
+		{Label: "d"},
+	}
+	m := newTestModel(WithItems(items))
+	byKey := m
+	resize(&m, 20, 10)
+	resize(&byKey, 20, 10)
+
+	steps := []struct {
+		down bool
+		want string
+	}{
+		{true, "c"}, {true, "d"}, {true, "d"},
+		{false, "c"}, {false, "a"}, {false, "a"},
+	}
+	for i, step := range steps {
+		index := m.Index()
+		next := m.CursorUp()
+		key := "up"
+		if step.down {
+			next, key = m.CursorDown(), "down"
+		}
+		if m.Index() != index {
+			t.Errorf("step %d: moving the cursor moved it on the model it was called on", i)
+		}
+		m = next
+		press(&byKey, key)
+		if got := m.Options[m.Index()]; got != step.want {
+			t.Errorf("step %d: cursor on %q, want %q", i, got, step.want)
+		}
+		if m.View() != byKey.View() {
+			t.Errorf("step %d: view is\n%s\nafter the same keys\n%s", i, m.View(), byKey.View())
+		}
+	}
+	if m.min != 0 {
+		t.Errorf("window at %d on the first option, want the note above it shown", m.min)
+	}
+}
+
+func TestDidSelectIneligible(t *testing.T) {
+	tests := []struct {
+		name  string
+		items []Option
+		setup func(m *Model)
+		msg   tea.KeyMsg
+	}{
+		{name: "blurred", setup: func(m *Model) { m.Blur() }},
+		{name: "filtering", setup: func(m *Model) { press(m, "/", "a") }},
+		{name: "all disabled", items: []Option{{Label: "a", Disabled: true}, {Label: "b", Disabled: true}}},
+		{name: "informational", items: []Option{{Label: "a", Kind: Info}}},
+		{name: "no options", items: []Option{}},
+		{
+			name:  "header",
+			items: []Option{{Label: "G", Kind: Header}, {Label: "a"}},
+			setup: func(m *Model) {
+				m.SelectableHeaders = true
+				press(m, "up")
+			},
+		},
+		{name: "parent", items: []Option{{Label: "p", Children: []Option{{Label: "a"}}}}},
+		{
+			name:  "shortcut of a disabled option",
+			items: []Option{{Label: "a"}, {Label: "b", Shortcut: "1", Disabled: true}},
+			msg:   keyMsg("1"),
+		},
+		{name: "loading", setup: func(m *Model) { m.SetLoading(true) }},
+		{name: "not the select key", msg: keyMsg("x")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items := tt.items
+			if items == nil {
+				items = []Option{{Label: "apple"}, {Label: "banana"}}
+			}
+			m := newTestModel(WithItems(items))
+			resize(&m, 20, 10)
+			if tt.setup != nil {
+				tt.setup(&m)
+			}
+			msg := tt.msg
+			if msg.Type == 0 && msg.Runes == nil {
+				msg = keyMsg("enter")
+			}
+			if ok, option := m.DidSelectOption(msg); ok {
+				t.Errorf("DidSelectOption = %q", option)
+			}
+			if ok, i := m.DidSelectIndex(msg); ok {
+				t.Errorf("DidSelectIndex = %d", i)
+			}
+			m.UpdateInPlace(msg)
+			if m.WasSubmitted() {
+				t.Error("Update selected an option")
+			}
+		})
+	}
+}
+
+func TestKeysWhileInputActive(t *testing.T) {
+	items := []Option{{Label: "jam"}, {Label: "bread", Shortcut: "b"}, {Label: "jelly"}}
+	for _, live := range []bool{false, true} {
+		m := newTestModel(WithItems(items))
+		m.LiveFilter = live
+		resize(&m, 30, 10)
+		if !live {
+			press(&m, "/")
+		}
+
+		// Letters go into the input, rather than moving the cursor with j and
+		// k or selecting the option b is the shortcut of.
+		for _, k := range []string{"j", "k", "b"} {
+			if ok, option := m.DidSelectOption(keyMsg(k)); ok {
+				t.Errorf("live %t: %q selected %q", live, k, option)
+			}
+			press(&m, k)
+		}
+		if m.FilterValue() != "jkb" || m.WasSubmitted() {
+			t.Errorf("live %t: typed jkb into %q, submitted %t", live, m.FilterValue(), m.WasSubmitted())
+		}
+
+		press(&m, "backspace", "backspace")
+		if !live {
+			// Enter applies the filter being edited without selecting.
+			if ok, option := m.DidSelectOption(keyMsg("enter")); ok {
+				t.Errorf("enter applying the filter selected %q", option)
+			}
+			press(&m, "enter")
+			if m.FilterState() != FilterApplied || m.WasSubmitted() {
+				t.Errorf("enter left the filter %v, submitted %t", m.FilterState(), m.WasSubmitted())
+			}
+			press(&m, "/")
+		}
+
+		// Esc clears the input, without leaving the picker.
+		press(&m, "esc")
+		if m.FilterValue() != "" || m.FilterState() != Unfiltered || m.WasCancelled() {
+			t.Errorf("live %t: esc left %q in the %v filter, cancelled %t", live, m.FilterValue(), m.FilterState(), m.WasCancelled())
+		}
+	}
+}
+
+func TestLiveFilterEnterSelects(t *testing.T) {
+	m := newTestModel(WithOptions([]string{"jam", "bread", "jelly"}))
+	m.LiveFilter = true
+	resize(&m, 30, 10)
+	press(&m, "b")
+	// A live filter holds no key but the ones editing its query, so Enter
+	// selects the best match.
+	if ok, option := m.DidSelectOption(keyMsg("enter")); !ok || option != "bread" {
+		t.Errorf("DidSelectOption = %t, %q, want bread", ok, option)
+	}
+}
+
+// loadedMsg runs the commands cmd batches, as LoadOptions returns them, and
+// returns the OptionsLoadedMsg one of them sends.
+func loadedMsg(t *testing.T, cmd tea.Cmd) OptionsLoadedMsg {
+	t.Helper()
+	msgs := make(chan tea.Msg, 8)
+	for _, c := range cmd().(tea.BatchMsg) {
+		if c != nil {
+			go func(c tea.Cmd) { msgs <- c() }(c)
+		}
+	}
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case msg := <-msgs:
+			if loaded, ok := msg.(OptionsLoadedMsg); ok {
+				return loaded
+			}
+		case <-timeout:
+			t.Fatal("the options weren't loaded")
+		}
+	}
+}
+
+func TestLoadCancelled(t *testing.T) {
+	// The loader ignores the context, as a slow one might, and brings its
+	// options once it's cancelled.
+	started := make(chan struct{})
+	slow := func(ctx context.Context) ([]Option, error) {
+		close(started)
+		<-ctx.Done()
+		return []Option{{Label: "late"}}, nil
+	}
+	m := newTestModel(WithOptions([]string{"old"}))
+	resize(&m, 20, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd := m.LoadOptionsCtx(ctx, slow)
+	go func() {
+		<-started
+		cancel()
+	}()
+	msg := loadedMsg(t, cmd)
+
+	// The picker is meanwhile reused for other options.
+	m.SetOptions([]string{"new"})
+	m.UpdateInPlace(msg)
+	if len(m.Options) != 1 || m.Options[0] != "new" {
+		t.Errorf("options are %q after the cancelled load came back, want new", m.Options)
+	}
+	if m.Loading() {
+		t.Error("still loading")
+	}
+	if err := m.Err(); err != nil {
+		t.Errorf("cancelled load shown as %v", err)
+	}
+}
+
+func TestLoadSuperseded(t *testing.T) {
+	m := newTestModel()
+	resize(&m, 20, 10)
+	first := m.LoadOptions(func() ([]Option, error) { return []Option{{Label: "first"}}, nil })
+	second := m.LoadOptions(func() ([]Option, error) { return []Option{{Label: "second"}}, nil })
+	late := loadedMsg(t, first)
+	m.UpdateInPlace(loadedMsg(t, second))
+	m.UpdateInPlace(late)
+	if len(m.Options) != 1 || m.Options[0] != "second" {
+		t.Errorf("options are %q, want those of the last load", m.Options)
+	}
+}
+
+// checkWindow fails t unless the window of m is a slice of the list holding
+// the cursor, with no blank lines below the options while there are more
+// than fit. Wrapped rows take up lines of their own, so the blank lines
+// aren't checked for while they wrap.
+func checkWindow(t *testing.T, m Model, step string) {
+	t.Helper()
+	n := m.lineCount()
+	r := m.cursorIndex()
+	switch {
+	case m.min < 0 || m.min >= max(n, 1):
+		t.Errorf("%s: window starts at %d of %d lines", step, m.min, n)
+	case !m.wrapping() && n >= m.size && m.min+m.size > n:
+		t.Errorf("%s: window of %d lines at %d runs past the %d lines", step, m.size, m.min, n)
+	case r != -1 && !m.inWindow(r):
+		t.Errorf("%s: cursor on %d outside the window of %d lines at %d", step, r, m.size, m.min)
+	}
+}
+
+func TestResizeWhileNavigating(t *testing.T) {
+	m := newTestModel(WithOptions(numbered(30)))
+	resize(&m, 20, 10)
+	steps := []struct {
+		keys   []string
+		height int
+	}{
+		{times(8, "down"), 10},
+		{nil, 4},
+		{[]string{"down", "down"}, 4},
+		{nil, 2},
+		{times(3, "up"), 2},
+		{nil, 12},
+		{times(21, "down"), 12},
+		{nil, 3},
+		{nil, 40},
+		{[]string{"up"}, 40},
+		{nil, 5},
+	}
+	for i, step := range steps {
+		press(&m, step.keys...)
+		before := m.cursorIndex()
+		resize(&m, 20, step.height)
+		if got := m.cursorIndex(); got != before {
+			t.Errorf("step %d: resizing to %d lines moved the cursor from %d to %d", i, step.height, before, got)
+		}
+		checkWindow(t, m, fmt.Sprintf("step %d, %d lines", i, step.height))
+		if lines := strings.Count(m.View(), "\n") + 1; lines > step.height {
+			t.Errorf("step %d: view is %d lines tall in %d lines", i, lines, step.height)
+		}
+	}
+}
+
+func TestPaginatedCursorPastSelected(t *testing.T) {
+	// Nothing is selectable before the header, so the cursor sits on it,
+	// past the row selected points at.
+	m := newTestModel(WithItems([]Option{
+		{Label: "disabled", Disabled: true},
+		{Label: "info", Kind: Info},
+		{Label: "info", Kind: Info},
+		{Label: "header", Kind: Header},
+	}))
+	m.SelectableHeaders, m.Paginated = true, true
+	resize(&m, 30, 5)
+	press(&m, "/")
+	if got := m.cursorIndex(); got != 3 || !m.inWindow(got) {
+		t.Errorf("cursor on row %d, page at %d to %d", got, m.min, m.windowMax())
+	}
+}
+
+func TestResizeBurst(t *testing.T) {
+	for _, layout := range []Layout{LayoutVertical, LayoutGrid} {
+		m := newTestModel(WithOptions(numbered(200)))
+		m.Layout = layout
+		m.ShowStatusBar = true
+		resize(&m, 60, 20)
+		press(&m, times(50, "down")...)
+		for i := 0; i < 30; i++ {
+			resize(&m, 20+i*3%50, 3+i*7%30)
+			checkWindow(t, m, fmt.Sprintf("layout %d, resize %d", layout, i))
+		}
+		if layout == LayoutGrid && !m.cellWidthOK || !m.selectableOK {
+			t.Errorf("layout %d: resizing dropped what's measured of the rows", layout)
+		}
+		resize(&m, 30, 8)
+		view := m.View()
+		// The grid has no status bar.
+		status := layout == LayoutGrid || strings.Contains(view, "51/200")
+		if lines := strings.Count(view, "\n") + 1; lines > 8 || !strings.Contains(view, m.Cursor+" o50") || !status {
+			t.Errorf("layout %d: view after a burst of resizes:\n%s", layout, view)
+		}
+	}
+}
+
+func TestTinyTerminal(t *testing.T) {
+	for height := 0; height <= 6; height++ {
+		m := newTestModel(WithOptions(numbered(20)))
+		m.HeightMargin = 5
+		m.Title = "Title"
+		m.ShowStatusBar = true
+		resize(&m, 20, height)
+		press(&m, times(4, "down")...)
+		if m.Height < 1 || !strings.Contains(m.View(), m.Cursor+" o4") {
+			t.Errorf("%d lines: Height %d, view:\n%s", height, m.Height, m.View())
+		}
+		checkWindow(t, m, fmt.Sprintf("%d lines", height))
+
+		// The window grows back with the terminal, still holding the cursor.
+		resize(&m, 20, 20)
+		press(&m, "down")
+		if m.Height != 20-5-2 || m.size != m.Height || !m.inWindow(5) {
+			t.Errorf("%d lines, grown to 20: Height %d, window at %d of %d lines", height, m.Height, m.min, m.size)
+		}
+	}
+}
+
+func TestHammerEnds(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for n := 0; n <= 12; n++ {
+		for height := 1; height <= 8; height++ {
+			for _, resized := range []bool{false, true} {
+				// Before any resize, the window is as set by WithHeight.
+				m := newTestModel(WithOptions(numbered(n)), WithHeight(height))
+				if resized {
+					resize(&m, 20, height)
+				}
+				keys := append(times(n+3, "down"), times(n+3, "up")...)
+				for i := 0; i < 40; i++ {
+					keys = append(keys, []string{"up", "down"}[r.Intn(2)])
+				}
+				for i, k := range keys {
+					press(&m, k)
+					checkWindow(t, m, fmt.Sprintf("%d options, %d lines, resized %t, key %d (%s)", n, height, resized, i, k))
+				}
+			}
+		}
+	}
+}
+
+func TestPopAtRoot(t *testing.T) {
+	var s stack
+	if top, ok := s.Pop(); ok || top != 0 {
+		t.Errorf("Pop() = %d, %t on an empty stack", top, ok)
+	}
+
+	m := newTestModel(WithOptions(numbered(10)))
+	resize(&m, 20, 4)
+	press(&m, times(6, "down")...)
+	selected, lo := m.selected, m.min
+	m.popView()
+	if m.PopMenu() {
+		t.Error("PopMenu() = true at the root")
+	}
+	if m.selected != selected || m.min != lo || m.Depth() != 0 || len(m.Options) != 10 {
+		t.Errorf("popping at the root moved the cursor to %d and the window to %d, at depth %d with %d options",
+			m.selected, m.min, m.Depth(), len(m.Options))
+	}
+}
+
+func TestMenuCopiesPushAndPop(t *testing.T) {
+	m := newTestModel(WithOptions(numbered(10)))
+	resize(&m, 20, 4)
+	press(&m, times(5, "down")...)
+
+	a, b := m, m
+	a.PushMenu([]Option{{Label: "a0"}, {Label: "a1"}}, nil, nil)
+	press(&a, "down")
+	b.PushMenu([]Option{{Label: "b0"}, {Label: "b1"}, {Label: "b2"}}, nil, nil)
+	press(&b, "down", "down")
+	c := b
+	c.PushMenu([]Option{{Label: "c0"}}, nil, nil)
+
+	if m.Depth() != 0 || len(m.Options) != 10 || m.selected != 5 {
+		t.Errorf("pushing menus on copies left the original at depth %d with %d options, cursor on %d", m.Depth(), len(m.Options), m.selected)
+	}
+	if a.Options[a.Index()] != "a1" || b.Options[b.Index()] != "b2" || c.Depth() != 2 || b.Depth() != 1 {
+		t.Errorf("copies are on %q at depth %d and %q at depth %d, and the copy of the second at depth %d",
+			a.Options[a.Index()], a.Depth(), b.Options[b.Index()], b.Depth(), c.Depth())
+	}
+
+	if !c.PopMenu() || c.Options[c.Index()] != "b2" {
+		t.Errorf("popping the deepest copy went back to %q, want b2", c.Options[c.Index()])
+	}
+	for name, p := range map[string]*Model{"a": &a, "b": &b, "c": &c} {
+		if !p.PopMenu() {
+			t.Fatalf("copy %s had no menu to pop", name)
+		}
+		if p.Depth() != 0 || len(p.Options) != 10 || p.selected != 5 || !p.inWindow(5) {
+			t.Errorf("copy %s popped back to depth %d with %d options, cursor on %d", name, p.Depth(), len(p.Options), p.selected)
+		}
+	}
+	if b.Depth() != 0 || c.PopMenu() {
+		t.Error("popped a menu twice")
+	}
+}
+
+func TestPopMenuClearsFilter(t *testing.T) {
+	m := newTestModel(WithOptions([]string{"one", "two"}))
+	resize(&m, 30, 10)
+	m.PushMenu([]Option{{Label: "o1"}, {Label: "o2"}, {Label: "o3"}, {Label: "o4"}}, nil, nil)
+	press(&m, "/", "o", "enter", "backspace")
+
+	if m.Depth() != 0 || m.FilterState() != Unfiltered || m.FilterInput.Value() != "" {
+		t.Fatalf("popped to depth %d with the filter %v on %q", m.Depth(), m.FilterState(), m.FilterInput.Value())
+	}
+	if got := m.FilteredOptions(); !slices.Equal(got, []string{"one", "two"}) {
+		t.Errorf("FilteredOptions() = %q after popping", got)
+	}
+	if view := m.View(); strings.Contains(view, "Filter") || !strings.Contains(view, "two") {
+		t.Errorf("parent shown with the submenu's filter:\n%s", view)
+	}
+}
+
+func TestRepairStaleWindow(t *testing.T) {
+	tests := []struct {
+		name    string
+		options int
+		height  int
+	}{
+		{"fewer than the window held", 3, 2},
+		{"fewer than fit", 3, 5},
+		{"one", 1, 1},
+		{"more, but above the cursor", 20, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(WithOptions(numbered(40)))
+			resize(&m, 20, 10)
+			press(&m, times(35, "down")...)
+			resize(&m, 20, tt.height)
+			m.Options = numbered(tt.options)
+
+			// View repairs the state it renders, without Update first.
+			view := m.View()
+			last := "o" + strconv.Itoa(tt.options-1)
+			if !strings.Contains(view, m.Cursor+" "+last) {
+				t.Errorf("view doesn't show the cursor on %s:\n%s", last, view)
+			}
+			press(&m, "up")
+			if !(0 <= m.min && m.min <= m.selected && m.selected <= m.windowMax() && m.windowMax() < len(m.Options)) {
+				t.Errorf("window at %d to %d, cursor on %d, with %d options", m.min, m.windowMax(), m.selected, len(m.Options))
+			}
+		})
+	}
+}
+
+func TestRepairStaleFilteredRows(t *testing.T) {
+	modes := []struct {
+		name string
+		set  func(*Model)
+	}{
+		{"plain", func(*Model) {}},
+		{"table, select many", func(m *Model) {
+			m.TableColumns = []Column{{Title: "Name"}, {Title: "Size", Width: 6}}
+			m.SelectionMode = SelectMany
+		}},
+		{"numbered", func(m *Model) { m.ShowNumbers = true }},
+		{"grid", func(m *Model) { m.Columns = 3 }},
+	}
+	for _, mode := range modes {
+		t.Run(mode.name, func(t *testing.T) {
+			// The closest matches are last, so the first rows are the ones
+			// shrinking Options drops.
+			m := newTestModel(WithOptions([]string{"a x b", "a x b", "a x b", "a x b", "ab", "ab"}))
+			mode.set(&m)
+			resize(&m, 40, 8)
+			m.SetFilterText("ab")
+			m.Options = m.Options[:3]
+
+			var view string
+			within(t, func() { view = m.View() })
+			if got := strings.Count(view, "a x b"); got != 3 || strings.Count(view, "ab") != 1 || !strings.Contains(view, "3/3") {
+				t.Errorf("view after shrinking to 3 options:\n%s", view)
+			}
+			press(&m, "down", "down", "down")
+			if n := m.rowCount(); n != 3 || m.optionIndex(m.selected) != 2 {
+				t.Errorf("%d rows, cursor on row %d of option %d", n, m.selected, m.optionIndex(m.selected))
+			}
+		})
+	}
+}
+
+func TestEveryKeyOnEmptyModel(t *testing.T) {
+	km := DefaultKeyMap()
+	var keys []string
+	for _, b := range []key.Binding{
+		km.Down, km.Up, km.Select, km.Toggle, km.Expand, km.Collapse, km.Left, km.Right,
+		km.PrevPage, km.NextPage, km.ToggleGroup, km.Back, km.DismissError, km.Retry, km.Filter, km.ClearFilter,
+		km.CancelWhileFiltering, km.AcceptWhileFiltering, km.CycleFilterMode,
+	} {
+		keys = append(keys, b.Keys()...)
+	}
+
+	tests := []struct {
+		name string
+		set  func(*Model)
+	}{
+		{"select one", func(*Model) {}},
+		{"select many", func(m *Model) { m.SelectionMode = SelectMany }},
+		{"radio", func(m *Model) { m.SelectionMode = SelectRadio }},
+		{"paginated", func(m *Model) { m.Paginated = true }},
+		{"grid", func(m *Model) { m.Columns = 3 }},
+		{"live filter", func(m *Model) { m.LiveFilter = true }},
+		{"wrapped, with hints", func(m *Model) { m.WrapLongOptions, m.ShowOverflowHints = true, true }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel()
+			tt.set(&m)
+			resize(&m, 30, 8)
+			var err error
+			within(t, func() {
+				// Each key twice, for the ones opening a state to close it
+				// again, in the order of the bindings then backwards.
+				for _, k := range append(append(slices.Clone(keys), keys...), reversed(keys)...) {
+					press(&m, k)
+					_ = m.View()
+					if m.selected != 0 || m.Index() != -1 {
+						err = fmt.Errorf("after %q: selected %d, index %d", k, m.selected, m.Index())
+						return
+					}
+				}
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+// reversed returns a copy of s in the reverse order.
+func reversed(s []string) []string {
+	r := slices.Clone(s)
+	slices.Reverse(r)
+	return r
+}
+
+func TestMutationsKeepCursorInView(t *testing.T) {
+	// groupedItems returns n rows starting a group every sixth one, with
+	// labels long enough to wrap in a narrow picker.
+	groupedItems := func(n int) []Option {
+		items := make([]Option, n)
+		for i := range items {
+			items[i] = Option{Label: "option " + strconv.Itoa(i) + " wrapping when narrow"}
+			if i%6 == 0 {
+				items[i] = Option{Label: "group " + strconv.Itoa(i/6), Kind: Header}
+			}
+		}
+		return items
+	}
+	mutations := []struct {
+		name   string
+		mutate func(*Model)
+	}{
+		{"shorter", func(m *Model) { m.SetHeight(3) }},
+		{"taller", func(m *Model) { m.SetHeight(40) }},
+		{"narrower", func(m *Model) { m.SetWidth(12) }},
+		{"wider", func(m *Model) { m.SetWidth(80) }},
+		{"fewer options", func(m *Model) { m.SetItems(groupedItems(len(m.Options) / 2)) }},
+		{"more options", func(m *Model) { m.SetItems(groupedItems(len(m.Options) * 2)) }},
+		{"filtered", func(m *Model) { m.SetFilterText("1") }},
+		{"collapsed", func(m *Model) {
+			for i := range m.Options {
+				m.SetGroupCollapsed(i, i%12 == 0)
+			}
+		}},
+	}
+	for _, wrap := range []bool{false, true} {
+		for _, n := range []int{5, 60} {
+			for _, downs := range []int{0, n / 2, n} {
+				for _, mu := range mutations {
+					name := fmt.Sprintf("wrap %t/%d options/%d down/%s", wrap, n, downs, mu.name)
+					t.Run(name, func(t *testing.T) {
+						m := newTestModel(WithItems(groupedItems(n)))
+						m.WrapLongOptions = wrap
+						resize(&m, 30, 10)
+						press(&m, times(downs, "down")...)
+						mu.mutate(&m)
+						checkWindow(t, m, "mutated")
+						view := m.View()
+						if m.cursorIndex() != -1 && !strings.Contains(view, m.Cursor+" ") {
+							t.Errorf("cursor on %d isn't in view:\n%s", m.cursorIndex(), view)
+						}
+						press(&m, "up", "down")
+						checkWindow(t, m, "moved")
+					})
+				}
+			}
+		}
+	}
+}
+
+func TestWrappedJumpToEnd(t *testing.T) {
+	options := make([]string, 5000)
+	for i := range options {
+		options[i] = "option " + strconv.Itoa(i) + " wrapping when narrow"
+	}
+	m := newTestModel(WithOptions(options))
+	m.WrapLongOptions = true
+	resize(&m, 16, 10)
+	// Jumping to the end measures the rows it lands among, not every row it
+	// passes.
+	within(t, func() { m.SetSelected(len(options) - 1) })
+	if m.selected != len(options)-1 || m.lastVisible() != m.selected {
+		t.Fatalf("cursor on row %d, window at %d to %d", m.selected, m.min, m.lastVisible())
+	}
+	lines := 0
+	for r := m.min; r <= m.selected; r++ {
+		lines += m.rowHeight(r)
+	}
+	if lines > m.size || lines+m.rowHeight(m.min-1) <= m.size {
+		t.Errorf("window from row %d takes %d lines of %d", m.min, lines, m.size)
+	}
+}
+
+func TestMultiLineLabels(t *testing.T) {
+	labels := []string{"one\nline", "two\r\nlines", "carriage\rreturn", "trailing\n", "plain"}
+	tests := []struct {
+		lineBreak string
+		shown     []string
+	}{
+		{" ", []string{"one line", "two lines", "carriage return", "trailing", "plain"}},
+		{" / ", []string{"one / line", "two / lines", "carriage / return", "trailing /", "plain"}},
+		{"", []string{"oneline", "twolines", "carriagereturn", "trailing", "plain"}},
+	}
+	for _, tt := range tests {
+		t.Run(strconv.Quote(tt.lineBreak), func(t *testing.T) {
+			m := newTestModel(WithOptions(labels))
+			m.LineBreak = tt.lineBreak
+			resize(&m, 40, 10)
+			for i, want := range tt.shown {
+				view := m.View()
+				if strings.ContainsAny(view, "\r") {
+					t.Fatalf("view holds a carriage return:\n%q", view)
+				}
+				if lines := strings.Count(view, "\n") + 1; lines != len(labels) {
+					t.Errorf("on %d: view has %d lines, want %d:\n%s", i, lines, len(labels), view)
+				}
+				if got := cursorLabel(t, m); got != want {
+					t.Errorf("on %d: cursor on %q, want %q", i, got, want)
+				}
+				selects, option := m.DidSelectOption(keyMsg("enter"))
+				if !selects || option != labels[i] {
+					t.Errorf("on %d: enter selects %t, %q, want %q", i, selects, option, labels[i])
+				}
+				press(&m, "down")
+			}
+		})
+	}
+}
+
+func TestFilterMultiLineLabel(t *testing.T) {
+	m := newColorModel(WithOptions([]string{"one\nline", "two\r\nlines", "plain"}))
+	resize(&m, 40, 10)
+	m.SetFilterText("lines")
+	if got := m.FilteredOptions(); len(got) != 1 || got[0] != "two\r\nlines" {
+		t.Fatalf("filter matches %q, want the label past the line break", got)
+	}
+	view := m.View()
+	if strings.ContainsAny(view, "\r") || !strings.Contains(stripEscapes(view), "two lines") {
+		t.Errorf("filtered view:\n%q", view)
+	}
+	// The matches are moved onto the runes of the label as it's shown.
+	if text, matches := m.labelText("two\r\nlines", []int{5, 6, 7, 8, 9}); text != "two lines" || !slices.Equal(matches, []int{4, 5, 6, 7, 8}) {
+		t.Errorf("label shown as %q with matches %v", text, matches)
+	}
+	if selects, option := m.DidSelectOption(keyMsg("enter")); !selects || option != "two\r\nlines" {
+		t.Errorf("enter selects %t, %q", selects, option)
+	}
+}
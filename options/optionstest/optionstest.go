@@ -0,0 +1,230 @@
+// Package optionstest drives pickers in the tests of programs embedding
+// them, and compares their views against golden files.
+package optionstest
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RemiG26/bubbles/options"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// Wait is how long Send waits for each command a picker returns. Commands
+// still running after it, such as the timers blinking the cursor, are
+// dropped along with the messages they would have sent.
+var Wait = 20 * time.Millisecond
+
+// Update has Golden write the views to the golden files instead of
+// comparing them. It's set when the OPTIONSTEST_UPDATE environment variable
+// isn't empty.
+var Update = os.Getenv("OPTIONSTEST_UPDATE") != ""
+
+// maxMsgs caps the number of messages Send handles, for commands that keep
+// sending messages within Wait not to run forever.
+const maxMsgs = 10000
+
+// Renderer returns a renderer that renders without colors or attributes
+// whatever the terminal, on a dark background, so that views are the same
+// wherever the tests run.
+func Renderer() *lipgloss.Renderer {
+	r := lipgloss.NewRenderer(io.Discard)
+	r.SetColorProfile(termenv.Ascii)
+	r.SetHasDarkBackground(true)
+	return r
+}
+
+// New returns a picker rendered with Renderer, configured by opts. Unlike
+// options.New, it leaves Accessible unset whatever the environment.
+func New(opts ...options.Opt) options.Model {
+	m := options.NewWithRenderer(Renderer(), opts...)
+	m.SetAccessible(false)
+	return m
+}
+
+// Run is what a picker was sent by Send and what it did with it.
+type Run struct {
+	// Msgs are the messages the picker was sent, in order, including the
+	// ones its commands sent.
+	Msgs []tea.Msg
+
+	// Selected are the options selected, in order, as DidSelectOption
+	// reports them on the messages selecting them.
+	Selected []string
+
+	// Quit is whether a command returned by the picker quit the program.
+	Quit bool
+}
+
+// Send updates m with msgs in order, as a program would. The commands m
+// returns are run and the messages they send within Wait are sent to m in
+// turn, after the ones already waiting. Nothing more is sent once one of
+// them quits the program.
+func Send(m *options.Model, msgs ...tea.Msg) Run {
+	var run Run
+	queue := append([]tea.Msg(nil), msgs...)
+	for len(queue) > 0 && len(run.Msgs) < maxMsgs {
+		msg := queue[0]
+		queue = queue[1:]
+		if sent, ok := commands(msg); ok {
+			queue = append(queue, sent...)
+			continue
+		}
+		if _, ok := msg.(tea.QuitMsg); ok {
+			run.Quit = true
+			break
+		}
+		run.Msgs = append(run.Msgs, msg)
+		if ok, option := m.DidSelectOption(msg); ok {
+			run.Selected = append(run.Selected, option)
+		}
+		if cmd := m.UpdateInPlace(msg); cmd != nil {
+			queue = append(queue, runAll([]tea.Cmd{cmd})...)
+		}
+	}
+	return run
+}
+
+// Type is Send with the keys named by keys, as Key returns them.
+func Type(m *options.Model, keys ...string) Run {
+	msgs := make([]tea.Msg, len(keys))
+	for i, k := range keys {
+		msgs[i] = Key(k)
+	}
+	return Send(m, msgs...)
+}
+
+// keyTypes maps the names of keys, as tea.KeyMsg.String reports them, to
+// their types.
+var keyTypes = func() map[string]tea.KeyType {
+	types := make(map[string]tea.KeyType)
+	for t := tea.KeyType(-256); t < 256; t++ {
+		if name := t.String(); name != "" && t != tea.KeyRunes {
+			types[name] = t
+		}
+	}
+	return types
+}()
+
+// Key returns the key press named name, as tea.KeyMsg.String reports it,
+// such as "down", "ctrl+c", " " or "alt+j". Any other name is typed as the
+// runes it's made of.
+func Key(name string) tea.KeyMsg {
+	var msg tea.KeyMsg
+	if rest, ok := strings.CutPrefix(name, "alt+"); ok && rest != "" {
+		msg.Alt = true
+		name = rest
+	}
+	if t, ok := keyTypes[name]; ok {
+		msg.Type = t
+		return msg
+	}
+	msg.Type = tea.KeyRunes
+	msg.Runes = []rune(name)
+	return msg
+}
+
+// commands returns the commands carried by a batch or a sequence of
+// commands, which a program runs rather than handing them to the model.
+// Sequenced commands are run one after the other.
+func commands(msg tea.Msg) ([]tea.Msg, bool) {
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		return runAll(batch), true
+	}
+	// Sequences are of a type of their own, holding commands as well.
+	v := reflect.ValueOf(msg)
+	cmds := reflect.TypeOf([]tea.Cmd(nil))
+	if v.Kind() != reflect.Slice || !v.Type().ConvertibleTo(cmds) {
+		return nil, false
+	}
+	var msgs []tea.Msg
+	for _, cmd := range v.Convert(cmds).Interface().([]tea.Cmd) {
+		msgs = append(msgs, runAll([]tea.Cmd{cmd})...)
+	}
+	return msgs, true
+}
+
+// runAll runs cmds at once and returns the messages they send within Wait,
+// in the order of the commands.
+func runAll(cmds []tea.Cmd) []tea.Msg {
+	results := make([]chan tea.Msg, len(cmds))
+	for i, cmd := range cmds {
+		if cmd == nil {
+			continue
+		}
+		results[i] = make(chan tea.Msg, 1)
+		go func(cmd tea.Cmd, result chan<- tea.Msg) {
+			result <- cmd()
+		}(cmd, results[i])
+	}
+	deadline := time.After(Wait)
+	var msgs []tea.Msg
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+		var msg tea.Msg
+		select {
+		case msg = <-result:
+		case <-deadline:
+			// Keep what the other commands have sent by now.
+			select {
+			case msg = <-result:
+			default:
+			}
+			deadline = closed
+		}
+		if msg != nil {
+			msgs = append(msgs, msg)
+		}
+	}
+	return msgs
+}
+
+// closed is a channel that's always ready, for a deadline that has passed.
+var closed = func() <-chan time.Time {
+	c := make(chan time.Time)
+	close(c)
+	return c
+}()
+
+// ansiSequence matches the escape sequences styling and moving around a
+// terminal.
+var ansiSequence = regexp.MustCompile("\x1b\\[[0-9;?]*[ -/]*[@-~]|\x1b\\][^\x07\x1b]*(\x07|\x1b\\\\)")
+
+// StripANSI returns s without the escape sequences in it.
+func StripANSI(s string) string {
+	return ansiSequence.ReplaceAllString(s, "")
+}
+
+// Golden compares view, with its escape sequences stripped, against the
+// golden file at path, failing t if they differ. With Update set, the file
+// is written with view instead, along with the directories it's in.
+func Golden(t testing.TB, path, view string) {
+	t.Helper()
+	got := StripANSI(view)
+	if Update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("optionstest: reading golden file: %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("optionstest: view differs from %s\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
@@ -0,0 +1,33 @@
+package optionstest
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		name string
+		want tea.KeyMsg
+	}{
+		{"down", tea.KeyMsg{Type: tea.KeyDown}},
+		{"ctrl+c", tea.KeyMsg{Type: tea.KeyCtrlC}},
+		{" ", tea.KeyMsg{Type: tea.KeySpace}},
+		{"alt+j", tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j"), Alt: true}},
+		{"abc", tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("abc")}},
+	}
+	for _, tt := range tests {
+		got := Key(tt.name)
+		if got.String() != tt.want.String() || got.Type != tt.want.Type {
+			t.Errorf("Key(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestStripANSI(t *testing.T) {
+	s := "\x1b[1;31mred\x1b[0m \x1b]8;;https://example.com\x07link\x1b]8;;\x07"
+	if got := StripANSI(s); got != "red link" {
+		t.Errorf("StripANSI(%q) = %q, want %q", s, got, "red link")
+	}
+}
@@ -0,0 +1,108 @@
+package options
+
+import "github.com/charmbracelet/bubbles/paginator"
+
+// newPaginator returns the paginator that renders the page indicator.
+func newPaginator() paginator.Model {
+	p := paginator.New()
+	p.Type = paginator.Dots
+	return p
+}
+
+// pageSize returns the number of rows on a page, which is the size of the
+// window.
+func (m Model) pageSize() int {
+	if m.size > 1 {
+		return m.size
+	}
+	return 1
+}
+
+// alignPage lines the window up with the page the cursor is on.
+func (m *Model) alignPage() {
+	per := m.pageSize()
+	m.min = m.line(m.selected) / per * per
+	m.size = per
+}
+
+// PageCount returns the number of pages of the window's size the options
+// make up, whether or not they're Paginated.
+func (m Model) PageCount() int {
+	per := m.pageSize()
+	return (m.lineCount() + per - 1) / per
+}
+
+// CurrentPage returns the page the window starts on, counting from zero.
+func (m Model) CurrentPage() int {
+	return m.min / m.pageSize()
+}
+
+// GotoPage scrolls the window to the start of page n, counting from zero
+// and clamped to the pages there are, and moves the cursor to the first
+// selectable option on it.
+func (m *Model) GotoPage(n int) {
+	m.Invalidate()
+	pages := m.PageCount()
+	if pages == 0 {
+		return
+	}
+	per := m.pageSize()
+	n = max(min(n, pages-1), 0)
+	m.min, m.size = n*per, per
+	for r := 0; r < m.rowCount(); r++ {
+		if line := m.line(r); m.selectable(r) && line >= m.min && line < m.min+m.size {
+			m.selected = r
+			break
+		}
+	}
+}
+
+// flipPage moves the cursor d pages forward or back, keeping its position
+// on the page where possible.
+func (m *Model) flipPage(d int) {
+	per := m.pageSize()
+	pages := (m.lineCount() + per - 1) / per
+	page := m.min/per + d
+	if page < 0 || page >= pages {
+		return
+	}
+	r := m.cursorIndex() + d*per
+	if n := m.rowCount(); r >= n {
+		r = n - 1
+	}
+	if !m.selectable(r) {
+		// Look for a selectable row on the new page, in the direction of
+		// travel first.
+		ahead, behind := m.nextSelectable(r), m.prevSelectable(r)
+		if d < 0 {
+			ahead, behind = behind, ahead
+		}
+		first, last := page*per, page*per+per-1
+		switch {
+		case ahead >= first && ahead <= last:
+			r = ahead
+		case behind >= first && behind <= last:
+			r = behind
+		default:
+			return
+		}
+	}
+	m.selected = r
+	m.followCursor()
+}
+
+// paginatorView renders the page indicator.
+func (m Model) paginatorView() string {
+	p := m.Paginator
+	p.PerPage = m.pageSize()
+	p.SetTotalPages(m.lineCount())
+	p.Page = m.min / p.PerPage
+	if p.TotalPages <= 1 {
+		return ""
+	}
+	if p.Type == paginator.Dots {
+		p.ActiveDot = m.Styles.ActivePaginationDot.String()
+		p.InactiveDot = m.Styles.InactivePaginationDot.String()
+	}
+	return m.Styles.Pagination.Render(p.View())
+}
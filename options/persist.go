@@ -0,0 +1,135 @@
+package options
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// State is what the user has done with the picker, for it to be saved, for
+// example as JSON, and applied again later with ApplyState. Options are
+// referred to by value, so that a state can be applied to a list that has
+// changed a little since.
+type State struct {
+	// Cursor is the value of the option on the cursor.
+	Cursor string `json:"cursor,omitempty"`
+
+	// Checked are the values of the checked options.
+	Checked []string `json:"checked,omitempty"`
+
+	// Filter is the filter text.
+	Filter string `json:"filter,omitempty"`
+
+	// Expanded are the values of the expanded parents of a tree, and
+	// Collapsed the ones of the collapsed group headers.
+	Expanded  []string `json:"expanded,omitempty"`
+	Collapsed []string `json:"collapsed,omitempty"`
+}
+
+// State returns the current state of the picker.
+func (m Model) State() State {
+	s := State{Filter: m.FilterInput.Value()}
+	if i := m.Index(); i != -1 {
+		s.Cursor = m.value(i)
+	}
+	for i := range m.Options {
+		v := m.value(i)
+		if m.Checked(i) {
+			s.Checked = append(s.Checked, v)
+		}
+		if m.branch(i) && m.nodes[i].expanded {
+			s.Expanded = append(s.Expanded, v)
+		}
+		if m.item(i).Kind == Header && m.groupCollapsed(i) {
+			s.Collapsed = append(s.Collapsed, v)
+		}
+	}
+	return s
+}
+
+// ApplyState applies a state returned by State. Values that none of the
+// options have are ignored, and the cursor moves to the first selectable
+// option if the one it was on is gone or hidden. The options s doesn't list
+// are unchecked, the parents collapsed and the groups expanded. The returned
+// command, if any, is the FilterAsync call.
+func (m *Model) ApplyState(s State) tea.Cmd {
+	m.Invalidate()
+	index := make(map[string]int, len(m.Options))
+	for i := len(m.Options) - 1; i >= 0; i-- {
+		index[m.value(i)] = i
+	}
+
+	m.checked = nil
+	for _, v := range s.Checked {
+		if i, ok := index[v]; ok {
+			m.SetChecked(i, true)
+		}
+	}
+	m.collapsed = nil
+	for _, v := range s.Collapsed {
+		if i, ok := index[v]; ok && m.item(i).Kind == Header {
+			m.setCollapsed(i, true)
+		}
+	}
+	if m.nodes != nil {
+		// Nodes are shared between copies of the model, so don't modify them
+		// in place.
+		ns := make(nodes, len(m.nodes))
+		copy(ns, m.nodes)
+		for i := range ns {
+			ns[i].expanded = false
+		}
+		for _, v := range s.Expanded {
+			if i, ok := index[v]; ok {
+				ns[i].expanded = true
+			}
+		}
+		m.nodes = ns
+	}
+	m.relayout()
+
+	cmd := m.SetFilterText(s.Filter)
+	m.selected = 0
+	if i, ok := index[s.Cursor]; ok && s.Cursor != "" {
+		m.reveal(i)
+		if r := m.rowOf(i); r != -1 && m.selectable(r) {
+			m.selected = r
+		}
+	}
+	if r := m.cursorIndex(); r != -1 {
+		m.selected = r
+	}
+	m.followCursor()
+	return cmd
+}
+
+// ViewState is the cursor, window and filter of a picker, saved by Snapshot
+// to be put back by Restore.
+type ViewState struct {
+	selected int
+	min      int
+	filter   string
+}
+
+// Snapshot returns the cursor, window and filter of the picker, for Restore
+// to put them back after the options have been replaced for a while.
+func (m Model) Snapshot() ViewState {
+	return ViewState{selected: m.selected, min: m.min, filter: m.FilterInput.Value()}
+}
+
+// Restore puts back the cursor, window and filter saved by Snapshot. If the
+// options have changed in between, the cursor and window are clamped to the
+// ones there are now. The returned command, if any, is the FilterAsync call.
+func (m *Model) Restore(v ViewState) tea.Cmd {
+	m.Invalidate()
+	cmd := m.SetFilterText(v.filter)
+	if n := m.rowCount(); n > 0 {
+		m.selected = max(min(v.selected, n-1), 0)
+	} else {
+		m.selected = 0
+	}
+	if m.Height > 0 {
+		m.min = max(min(v.min, m.lineCount()-m.size), 0)
+	}
+	if r := m.cursorIndex(); r != -1 {
+		m.selected = r
+	}
+	m.followCursor()
+	return cmd
+}
@@ -0,0 +1,65 @@
+package options
+
+import (
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// plain returns whether the renderer can't show colors, as when NO_COLOR is
+// set, the terminal is dumb or the output isn't a terminal, or whether the
+// colors are dropped with Accessible. The picker then doesn't rely on
+// styles alone to show where the cursor is.
+func (m Model) plain() bool {
+	if m.Accessible {
+		return true
+	}
+	r := m.renderer
+	if r == nil {
+		r = lipgloss.DefaultRenderer()
+	}
+	return r.ColorProfile() == termenv.Ascii
+}
+
+// hideCursorGutter returns whether the cursor and its gutter are left out.
+// They're kept without colors, since the cursor is then all there is to tell
+// the row on it apart.
+func (m Model) hideCursorGutter() bool {
+	return m.HideCursorGutter && !m.plain()
+}
+
+// plainGlyphs returns g, or the ASCII marks when g holds any others and the
+// renderer can't show colors, as such terminals often can't show them either.
+// With Accessible the ASCII marks are always used, for the words they stand
+// for to be read the same way whatever Glyphs holds.
+func (m Model) plainGlyphs(g Glyphs) Glyphs {
+	if !m.plain() {
+		return g
+	}
+	if m.Accessible {
+		return DefaultGlyphs()
+	}
+	for _, s := range []string{g.Unchecked, g.Checked, g.RadioOff, g.RadioOn} {
+		for _, r := range s {
+			if r >= utf8.RuneSelf {
+				return DefaultGlyphs()
+			}
+		}
+	}
+	return g
+}
+
+// plainSelected applies PlainSelected to the label of the option on the
+// cursor when the renderer can't show colors. The filter matches are
+// dropped when it changes the number of runes of the label.
+func (m Model) plainSelected(name string, matches []int) (string, []int) {
+	if m.PlainSelected == nil || !m.plain() {
+		return name, matches
+	}
+	s := singleLine(m.PlainSelected(name))
+	if utf8.RuneCountInString(s) != utf8.RuneCountInString(name) {
+		matches = nil
+	}
+	return s, matches
+}
@@ -0,0 +1,38 @@
+package options
+
+import (
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// sgr matches the escape sequences setting colors and text attributes.
+var sgr = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+func TestHideCursorGutter(t *testing.T) {
+	r := lipgloss.NewRenderer(io.Discard)
+	r.SetColorProfile(termenv.ANSI)
+	m := NewWithRenderer(r, WithOptions([]string{"a", "b"}))
+	m.SelectionMode = SelectMany
+	m.HideCursorGutter = true
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 20, Height: 10})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+
+	if !m.Focused() {
+		t.Error("picker blurred with HideCursorGutter set")
+	}
+	for _, line := range strings.Split(sgr.ReplaceAllString(m.View(), ""), "\n") {
+		if !strings.HasPrefix(line, "[ ]") {
+			t.Errorf("line %q doesn't start with its checkbox", line)
+		}
+	}
+	m.HideCursorGutter = false
+	if view := sgr.ReplaceAllString(m.View(), ""); !strings.HasPrefix(view, "  [ ] a\n") {
+		t.Errorf("with the gutter:\n%s", view)
+	}
+}
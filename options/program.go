@@ -0,0 +1,30 @@
+package options
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// Program wraps a Model to implement tea.Model, for the picker to be used
+// where one is expected. Its Model field holds the picker as it's updated,
+// for its state to be read.
+type Program struct {
+	Model Model
+}
+
+// AsTeaModel returns m wrapped as a tea.Model.
+func AsTeaModel(m Model) *Program {
+	return &Program{Model: m}
+}
+
+// Init initializes the picker.
+func (p *Program) Init() tea.Cmd {
+	return p.Model.Init()
+}
+
+// Update updates the picker with msg.
+func (p *Program) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	return p, p.Model.UpdateInPlace(msg)
+}
+
+// View renders the picker.
+func (p *Program) View() string {
+	return p.Model.View()
+}
@@ -0,0 +1,169 @@
+package options
+
+import (
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Provider serves the options of a picker on demand, for lists too long or
+// too slow to read in full. Len is called from Update and should return at
+// once. At may take its time: it's only called from the commands the picker
+// returns, for the rows around the window.
+type Provider interface {
+	Len() int
+	At(i int) Option
+}
+
+// providerBlock is the number of options fetched from a Provider together.
+const providerBlock = 64
+
+// rowsFetchedMsg carries the options of a run of blocks fetched from the
+// Provider, starting at block from.
+type rowsFetchedMsg struct {
+	id, seq int
+	from    int
+	items   []Option
+}
+
+// SetProvider replaces the options with the len options of p, fetched as
+// the window scrolls to them, along with Lookahead rows above and below it.
+// Until its option arrives a row shows Ellipsis, and can't be selected,
+// checked or found by the filter. The Children, Hidden, Collapsed and Checked
+// fields of the options provided are ignored. The returned command fetches the rows of the
+// first window. Setting the options in any other way drops the provider,
+// along with the fetches still running.
+func (m *Model) SetProvider(p Provider) tea.Cmd {
+	n := p.Len()
+	options := make([]string, n)
+	for i := range options {
+		options[i] = m.Ellipsis
+	}
+	m.setItems(options, nil, nil)
+	m.provider = p
+	m.provided = make([][]Option, (n+providerBlock-1)/providerBlock)
+	return m.prefetch()
+}
+
+// pending returns whether the option at index i of Options is still to
+// arrive from the Provider.
+func (m Model) pending(i int) bool {
+	b := i / providerBlock
+	return m.provider != nil && b < len(m.provided) && m.provided[b] == nil
+}
+
+// providedItem returns the option at index i of Options as the Provider
+// served it, or the zero Option while it's pending.
+func (m Model) providedItem(i int) Option {
+	b := i / providerBlock
+	if b >= len(m.provided) || i%providerBlock >= len(m.provided[b]) {
+		return Option{}
+	}
+	item := m.provided[b][i%providerBlock]
+	item.Children, item.Hidden = nil, false
+	return item
+}
+
+// prefetch returns the commands fetching the blocks of the rows in and
+// around the window that haven't arrived or been asked for, a command per
+// run of blocks.
+func (m *Model) prefetch() tea.Cmd {
+	if m.provider == nil {
+		return nil
+	}
+	look := m.Lookahead
+	if look <= 0 {
+		look = m.size
+	}
+	cursor := max(m.cursorIndex(), 0)
+	var spans [][2]int
+	if m.Layout == LayoutGrid {
+		_, rows := m.gridSize()
+		for col := 0; col*rows < m.rowCount(); col++ {
+			spans = append(spans, [2]int{col*rows + m.min - look, col*rows + m.min + m.size + look})
+		}
+	} else {
+		spans = append(spans, [2]int{min(m.min, cursor) - look, max(m.min+m.size, cursor+1) + look})
+	}
+
+	var want []int
+	for _, span := range spans {
+		for r := max(span[0], 0); r < min(span[1], m.rowCount()); r++ {
+			i := m.optionIndex(r)
+			if i == -1 || !m.pending(i) {
+				continue
+			}
+			b := i / providerBlock
+			if b < len(m.requested) && m.requested[b] {
+				continue
+			}
+			if n := len(want); n == 0 || want[n-1] != b {
+				want = append(want, b)
+			}
+		}
+	}
+	if len(want) == 0 {
+		return nil
+	}
+	slices.Sort(want)
+	want = slices.Compact(want)
+
+	// The blocks asked for are shared between copies of the model, so don't
+	// mark them in place.
+	requested := make([]bool, len(m.provided))
+	copy(requested, m.requested)
+	var cmds []tea.Cmd
+	for start := 0; start < len(want); {
+		end := start + 1
+		for end < len(want) && want[end] == want[end-1]+1 {
+			end++
+		}
+		for _, b := range want[start:end] {
+			requested[b] = true
+		}
+		cmds = append(cmds, m.fetchBlocks(want[start], want[end-1]+1))
+		start = end
+	}
+	m.requested = requested
+	return tea.Batch(cmds...)
+}
+
+// fetchBlocks returns the command fetching the options of blocks from to
+// to, exclusive, from the Provider.
+func (m Model) fetchBlocks(from, to int) tea.Cmd {
+	id, seq, p := m.id, m.providerSeq, m.provider
+	n := len(m.Options)
+	return func() tea.Msg {
+		items := make([]Option, 0, (to-from)*providerBlock)
+		for i := from * providerBlock; i < min(to*providerBlock, n); i++ {
+			items = append(items, p.At(i))
+		}
+		return rowsFetchedMsg{id: id, seq: seq, from: from, items: items}
+	}
+}
+
+// handleRowsFetched shows the options fetched from the Provider, unless it
+// has been replaced since they were asked for.
+func (m *Model) handleRowsFetched(msg rowsFetchedMsg) {
+	if msg.id != m.id || msg.seq != m.providerSeq || m.provider == nil {
+		return
+	}
+	// The options are shared between copies of the model, so don't set them
+	// in place.
+	options := slices.Clone(m.Options)
+	provided := slices.Clone(m.provided)
+	for j := 0; j < len(msg.items); j += providerBlock {
+		b := msg.from + j/providerBlock
+		if b >= len(provided) {
+			break
+		}
+		block := msg.items[j:min(j+providerBlock, len(msg.items))]
+		provided[b] = block
+		for k, item := range block {
+			if i := b*providerBlock + k; i < len(options) {
+				options[i] = item.Label
+			}
+		}
+	}
+	m.Options, m.provided = options, provided
+}
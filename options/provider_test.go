@@ -0,0 +1,146 @@
+package options
+
+import (
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// countingProvider provides n numbered options, counting the ones served.
+type countingProvider struct {
+	n   int
+	ats atomic.Int64
+}
+
+func (p *countingProvider) Len() int { return p.n }
+
+func (p *countingProvider) At(i int) Option {
+	p.ats.Add(1)
+	return Option{Label: "p" + strconv.Itoa(i), Value: strconv.Itoa(i)}
+}
+
+// fetch runs cmd and the commands updating m with what it returns leads to,
+// until none are left.
+func fetch(m *Model, cmd tea.Cmd) {
+	cmds := []tea.Cmd{cmd}
+	for len(cmds) > 0 {
+		cmd, cmds = cmds[0], cmds[1:]
+		if cmd == nil {
+			continue
+		}
+		switch msg := cmd().(type) {
+		case tea.BatchMsg:
+			cmds = append(cmds, msg...)
+		case rowsFetchedMsg:
+			cmds = append(cmds, m.UpdateInPlace(msg))
+		}
+	}
+}
+
+func TestProviderFetchesAroundWindow(t *testing.T) {
+	p := &countingProvider{n: 1_000_000}
+	m := newTestModel()
+	resize(&m, 40, 12)
+	fetch(&m, m.SetProvider(p))
+	if len(m.Options) != p.n {
+		t.Fatalf("%d options, want %d", len(m.Options), p.n)
+	}
+	if got := p.ats.Load(); got == 0 || got > 4*providerBlock {
+		t.Errorf("%d options fetched for the first window", got)
+	}
+	if got := cursorLabel(t, m); got != "p0" {
+		t.Errorf("cursor on %q, want p0", got)
+	}
+
+	before := p.ats.Load()
+	m.SetSelected(p.n - 1)
+	fetch(&m, m.UpdateInPlace(nil))
+	if got := p.ats.Load() - before; got == 0 || got > 4*providerBlock {
+		t.Errorf("%d options fetched jumping to the end", got)
+	}
+	if got := cursorLabel(t, m); got != "p999999" {
+		t.Errorf("cursor on %q, want p999999", got)
+	}
+	if ok, label := m.DidSelectOption(keyMsg("enter")); !ok || label != "p999999" {
+		t.Errorf("selected %q, %v", label, ok)
+	}
+	if got := m.pending(p.n / 2); !got {
+		t.Error("the options in the middle were fetched")
+	}
+}
+
+func TestProviderPlaceholders(t *testing.T) {
+	m := newTestModel()
+	resize(&m, 40, 12)
+	cmd := m.SetProvider(&countingProvider{n: 1000})
+	if cmd == nil {
+		t.Fatal("no command fetching the first window")
+	}
+	if view := m.View(); strings.Contains(view, "p0") || !strings.Contains(view, m.Ellipsis) {
+		t.Errorf("view before the fetch:\n%s", view)
+	}
+
+	// Nothing is selected, or checked, before it arrives.
+	m.SelectionMode = SelectMany
+	press(&m, " ")
+	if m.Checked(0) {
+		t.Error("checked a placeholder")
+	}
+	m.SelectionMode = SelectOne
+	if ok, _ := m.DidSelectOption(keyMsg("enter")); ok {
+		t.Error("selected a placeholder")
+	}
+
+	fetch(&m, cmd)
+	if view := m.View(); !strings.Contains(view, "p0") {
+		t.Errorf("view after the fetch:\n%s", view)
+	}
+	if ok, _ := m.DidSelectOption(keyMsg("enter")); !ok {
+		t.Error("didn't select a fetched option")
+	}
+}
+
+func TestProviderStaleFetch(t *testing.T) {
+	m := newTestModel()
+	resize(&m, 40, 12)
+	cmd := m.SetProvider(&countingProvider{n: 1000})
+	m.SetOptions([]string{"a", "b"})
+	fetch(&m, cmd)
+	if len(m.Options) != 2 || m.Options[0] != "a" {
+		t.Errorf("options %q after a fetch for the provider replaced", m.Options[:min(len(m.Options), 3)])
+	}
+
+	// Fetches of the parent while a submenu is shown are dropped, and made
+	// again once it's popped.
+	p := &countingProvider{n: 1000}
+	cmd = m.SetProvider(p)
+	m.PushMenu([]Option{{Label: "sub"}}, nil, nil)
+	fetch(&m, cmd)
+	if len(m.Options) != 1 || m.Options[0] != "sub" {
+		t.Errorf("submenu options %q", m.Options)
+	}
+	m.PopMenu()
+	fetch(&m, m.UpdateInPlace(nil))
+	if got := cursorLabel(t, m); got != "p0" {
+		t.Errorf("cursor on %q after popping the submenu, want p0", got)
+	}
+}
+
+func TestProviderFilter(t *testing.T) {
+	m := newTestModel()
+	resize(&m, 40, 12)
+	fetch(&m, m.SetProvider(&countingProvider{n: 100_000}))
+	m.SetFilterMode(FilterModeSubstring)
+	m.SetFilterText("p1")
+	for _, label := range m.VisibleOptions() {
+		if !strings.Contains(label, "p1") {
+			t.Errorf("placeholder or mismatch %q shown under the filter", label)
+		}
+	}
+	if len(m.VisibleOptions()) == 0 {
+		t.Error("no fetched option found by the filter")
+	}
+}
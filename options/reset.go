@@ -0,0 +1,65 @@
+package options
+
+// Reset puts the picker back in the state its options were set in: it leaves
+// any submenus for the root menu, clears the filter and the status message,
+// checks, collapses and expands the options as set by their Checked,
+// Collapsed and Expanded fields, and moves the cursor back to the first
+// selectable option at the top of a window sized for the current height.
+// The options, key bindings and styles of the root menu are kept.
+func (m *Model) Reset() {
+	m.Invalidate()
+	if len(m.menus) > 0 {
+		root := m.menus[0]
+		m.Options = root.options
+		m.items = root.items
+		m.nodes = root.nodes
+		m.Styles = root.styles
+		m.KeyMap = root.keyMap
+	}
+	m.menus = nil
+	m.minStack, m.sizeStack, m.selectedStack = nil, nil, nil
+	m.slide = nil
+
+	m.resetFilter()
+	m.FilterInput.Reset()
+	m.statusMessage = ""
+	// Let the timer of the message cleared go off without effect.
+	m.statusSeq++
+	m.loadErr = nil
+	m.err = nil
+	m.result, m.submitted, m.cancelled = Selection{}, false, false
+	m.marqueeOffset, m.marqueeWait = 0, 0
+
+	m.collapsed = nil
+	m.checked = nil
+	m.revealed = -1
+	if m.nodes != nil {
+		// Nodes are shared between copies of the model, so don't modify them
+		// in place.
+		ns := make(nodes, len(m.nodes))
+		copy(ns, m.nodes)
+		m.nodes = ns
+	}
+	for i := range m.Options {
+		item := m.item(i)
+		if item.Kind == Header && item.Collapsed {
+			m.setCollapsed(i, true)
+		}
+		if item.Checked {
+			m.SetChecked(i, true)
+		}
+		if m.nodes != nil {
+			m.nodes[i].expanded = item.Expanded
+		}
+	}
+
+	m.min = 0
+	m.sizeWindow()
+	m.resetRows()
+}
+
+// ResetAll resets the picker like Reset and removes all of its options.
+func (m *Model) ResetAll() {
+	m.Reset()
+	m.SetItems(nil)
+}
@@ -0,0 +1,84 @@
+package options
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// RetryPolicy sets how a load started by LoadOptions is retried when it
+// fails.
+type RetryPolicy struct {
+	// Attempts is the number of times the options are loaded before the
+	// load gives up, counting the first. One or less doesn't retry.
+	Attempts int
+
+	// Backoff is how long to wait before the second attempt. It doubles
+	// before each attempt after that.
+	Backoff time.Duration
+}
+
+type retryTickMsg struct {
+	id  int
+	seq int
+}
+
+// RetryLoad starts the last load of LoadOptions over, as the Retry key does,
+// once it has failed. It returns nil if there is no failed load to retry.
+func (m *Model) RetryLoad() tea.Cmd {
+	if !m.canRetry() {
+		return nil
+	}
+	m.Invalidate()
+	m.err = nil
+	m.attempt = 1
+	return m.startLoad()
+}
+
+// canRetry returns whether there is a failed load of LoadOptions to retry.
+func (m Model) canRetry() bool {
+	return m.load != nil && m.loadErr != nil && !m.loading
+}
+
+// retry schedules the next attempt at the current load, which failed,
+// returning nil when the load is to give up instead.
+func (m *Model) retry() tea.Cmd {
+	if m.attempt >= m.Retry.Attempts || m.loadCtx.Err() != nil {
+		return nil
+	}
+	backoff := m.Retry.Backoff << (m.attempt - 1)
+	m.attempt++
+	m.retryAt = time.Now().Add(backoff)
+	return m.retryTick()
+}
+
+// retryTick returns the command ticking down to the next attempt, once a
+// second for the countdown shown.
+func (m Model) retryTick() tea.Cmd {
+	id, seq := m.id, m.loadSeq
+	return tea.Tick(min(time.Until(m.retryAt), time.Second), func(time.Time) tea.Msg {
+		return retryTickMsg{id: id, seq: seq}
+	})
+}
+
+// handleRetryTick starts the next attempt once it's due, unless another load
+// has been started since it was scheduled.
+func (m *Model) handleRetryTick(msg retryTickMsg) tea.Cmd {
+	if msg.id != m.id || msg.seq != m.loadSeq || m.retryAt.IsZero() {
+		return nil
+	}
+	if time.Until(m.retryAt) > 0 {
+		return m.retryTick()
+	}
+	m.retryAt = time.Time{}
+	return m.startLoad()
+}
+
+// retryingView renders the countdown to the next attempt in place of
+// LoadingMessage.
+func (m Model) retryingView() string {
+	wait := wholeSeconds(max(time.Until(m.retryAt), 0))
+	msg := fmt.Sprintf(m.RetryingMessage, wait, m.attempt, m.Retry.Attempts)
+	return m.Styles.Loading.Render(m.Spinner.View() + " " + msg)
+}
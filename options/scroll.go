@@ -0,0 +1,31 @@
+package options
+
+// ScrollBehavior describes how the window follows the cursor.
+type ScrollBehavior int
+
+// Available scroll behaviors.
+const (
+	// ScrollEdge scrolls the window once the cursor moves past its top or
+	// bottom edge.
+	ScrollEdge ScrollBehavior = iota
+
+	// ScrollCentered keeps the cursor on the middle line of the window,
+	// scrolling the options around it, except near the ends of the list
+	// where the window stops at the first or last option. It doesn't apply
+	// to paginated or wrapped options, which scroll at the edges.
+	ScrollCentered
+)
+
+// centerCursor scrolls the window so that line, the line of the cursor, is
+// in its middle, without scrolling past either end of the list.
+func (m *Model) centerCursor(line int) {
+	size := m.size
+	top := line - (size-1)/2
+	if last := m.lineCount() - size; top > last {
+		top = last
+	}
+	if top < 0 {
+		top = 0
+	}
+	m.min = top
+}
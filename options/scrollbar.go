@@ -0,0 +1,57 @@
+package options
+
+import "strings"
+
+const (
+	scrollbarThumb = "█"
+	scrollbarTrack = "│"
+)
+
+// overflows returns whether some of the rows are out of view.
+func (m Model) overflows() bool {
+	return m.min > 0 || m.lastVisible() < m.rowCount()-1
+}
+
+// withScrollbar draws a scrollbar to the right of the given lines, which show
+// the rows in view. The lines are padded to width cells first, or to the
+// widest of them when width is zero, so that the scrollbar forms a straight
+// column. Nothing is drawn when all the rows are in view.
+func (m Model) withScrollbar(lines []string, width int) []string {
+	if len(lines) == 0 || !m.overflows() {
+		return lines
+	}
+	if width <= 0 {
+		for _, line := range lines {
+			if w := stringWidth(line); w > width {
+				width = w
+			}
+		}
+	}
+
+	// Size and place the thumb in proportion to the rows in view.
+	track, total := len(lines), m.rowCount()
+	thumb := track * track / total
+	if thumb < 1 {
+		thumb = 1
+	}
+	top := 0
+	if hidden := total - track; hidden > 0 {
+		top = (m.min*(track-thumb) + hidden/2) / hidden
+	}
+	if top > track-thumb {
+		top = track - thumb
+	}
+
+	for i, line := range lines {
+		if pad := width - stringWidth(line); pad > 0 {
+			line += strings.Repeat(" ", pad)
+		}
+		if i >= top && i < top+thumb {
+			line += m.Styles.ScrollbarThumb.Render(scrollbarThumb)
+		} else {
+			line += m.Styles.ScrollbarTrack.Render(scrollbarTrack)
+		}
+		lines[i] = line
+	}
+	return lines
+}
@@ -0,0 +1,96 @@
+package options
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// secondaryGap is the least space kept between a label and its
+	// secondary text.
+	secondaryGap = 2
+
+	// secondaryMinLabel is the least width a label is cut down to to make
+	// room for secondary text.
+	secondaryMinLabel = 8
+)
+
+// trailing returns the secondary text and the shortcut hint shown at the
+// right of row r, and the width they take from a row of width cells, gaps
+// included. prefix and name are the tree prefix and label of the row. The
+// hint is left out first when there's too little room left for the label.
+func (m Model) trailing(r, width int, prefix, name string) (string, string, int) {
+	hint, hintW := m.fitTrailing(r, width, prefix, name, m.shortcut(r))
+	if width > 0 {
+		width -= hintW
+	}
+	sec, secW := m.secondaryText(r, width, prefix, name)
+	return sec, hint, secW + hintW
+}
+
+// withTrailing appends the secondary text and shortcut hint of row r to
+// line, the first line of the row.
+func (m Model) withTrailing(r, cursor int, line, sec, hint string, width int) string {
+	secWidth := width
+	if width > 0 && hint != "" {
+		secWidth -= stringWidth(hint) + secondaryGap
+	}
+	line = m.withSecondary(r, cursor, line, sec, secWidth)
+	return m.appendTrailing(r, cursor, line, hint, m.Styles.Shortcut, width)
+}
+
+// secondaryText returns the secondary text of row r, cut to
+// SecondaryMaxWidth, and the width it takes from a row of width cells, gap
+// included. prefix and name are the tree prefix and label of the row. The
+// text is left out when it would leave too little room for the label.
+func (m Model) secondaryText(r, width int, prefix, name string) (string, int) {
+	i := m.optionIndex(r)
+	if m.SecondaryText == nil || i == -1 || m.item(i).Kind != Selectable {
+		return "", 0
+	}
+	text := singleLine(m.SecondaryText(i))
+	if m.SecondaryMaxWidth > 0 {
+		text, _ = truncate(text, m.SecondaryMaxWidth, m.Ellipsis)
+	}
+	return m.fitTrailing(r, width, prefix, name, text)
+}
+
+// fitTrailing returns text and the width it takes from a row of width cells,
+// gap included, or nothing when it would leave too little room for the label
+// of row r.
+func (m Model) fitTrailing(r, width int, prefix, name, text string) (string, int) {
+	if text == "" {
+		return "", 0
+	}
+	w := stringWidth(text) + secondaryGap
+	if width > 0 {
+		avail := width - m.leadWidth(r, prefix) - w
+		if avail < min(stringWidth(name), secondaryMinLabel) {
+			return "", 0
+		}
+	}
+	return text, w
+}
+
+// withSecondary appends text, the secondary text of row r, to line, the
+// first line of the row. It's aligned to the right of width cells, or
+// follows the label when the width is unbounded.
+func (m Model) withSecondary(r, cursor int, line, text string, width int) string {
+	return m.appendTrailing(r, cursor, line, text, m.Styles.Secondary, width)
+}
+
+// appendTrailing appends text rendered with style to line, aligned to the
+// right of width cells, or following it when the width is unbounded.
+func (m Model) appendTrailing(r, cursor int, line, text string, style lipgloss.Style, width int) string {
+	if text == "" {
+		return line
+	}
+	pad := secondaryGap
+	if width > 0 {
+		pad = max(width-stringWidth(line)-stringWidth(text), secondaryGap)
+	}
+	bar := m.padStyle(r, cursor)
+	return line + bar.Render(strings.Repeat(" ", pad)) +
+		withBackground(m.styleFor(r, style), bar).Render(text)
+}
@@ -0,0 +1,59 @@
+package options
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// shortcut returns the shortcut of the option on row r, if it can be
+// selected. Keys bound in the KeyMap aren't shortcuts, and neither are any
+// while LiveFilter takes the keys typed.
+func (m Model) shortcut(r int) string {
+	if m.LiveFilter || !m.selectable(r) || m.branch(m.optionIndex(r)) {
+		return ""
+	}
+	s := m.item(m.optionIndex(r)).Shortcut
+	if m.KeyMap.bound(s) {
+		return ""
+	}
+	return s
+}
+
+// shortcutRow returns the row of the option whose shortcut msg is, or -1 if
+// there is none. Keys bound in the KeyMap return before the rows are looked
+// through, as they're never shortcuts, so that navigating doesn't cost more
+// as the list grows.
+func (m Model) shortcutRow(msg tea.KeyMsg) int {
+	k := msg.String()
+	if m.LiveFilter || len(m.items) == 0 || m.KeyMap.bound(k) {
+		return -1
+	}
+	for r := 0; r < m.rowCount(); r++ {
+		// Only look further at the options the shortcut is set on.
+		i := m.optionIndex(r)
+		if i >= 0 && i < len(m.items) && m.items[i].Shortcut == k && m.shortcut(r) != "" {
+			return r
+		}
+	}
+	return -1
+}
+
+// bound returns whether k is one of the keys of the enabled bindings of the
+// KeyMap.
+func (km KeyMap) bound(k string) bool {
+	for _, b := range []key.Binding{
+		km.Down, km.Up, km.Select, km.Toggle, km.Expand, km.Collapse, km.Left, km.Right,
+		km.PrevPage, km.NextPage, km.ToggleGroup, km.Back, km.DismissError, km.Retry, km.Filter, km.ClearFilter,
+		km.CancelWhileFiltering, km.AcceptWhileFiltering, km.CycleFilterMode,
+	} {
+		if !b.Enabled() {
+			continue
+		}
+		for _, bk := range b.Keys() {
+			if bk == k {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+package options
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/reflow/ansi"
+)
+
+// DisableAnimations turns off the animations of all pickers, such as the
+// slide between submenus, for terminals that don't cope well with them.
+var DisableAnimations bool
+
+// slideFrames is the number of frames a slide between submenus is drawn in.
+const slideFrames = 8
+
+// slide is a slide between the options of two levels of submenus.
+type slide struct {
+	// from is the view of the level that was left.
+	from string
+	// back is whether the slide goes back up to a parent level.
+	back  bool
+	frame int
+	seq   int
+}
+
+type slideMsg struct {
+	id  int
+	seq int
+}
+
+// startSlide starts sliding from the options shown in from to the ones of
+// the level entered, returning the command that draws the next frame, or nil
+// if there's no slide to draw.
+func (m *Model) startSlide(from string, back bool) tea.Cmd {
+	if DisableAnimations || m.SlideDuration <= 0 {
+		return nil
+	}
+	m.Invalidate()
+	m.slide = &slide{from: from, back: back, seq: m.slideSeq()}
+	return m.slideTick()
+}
+
+// slideSeq returns the sequence number of the next slide.
+func (m Model) slideSeq() int {
+	if m.slide == nil {
+		return 1
+	}
+	return m.slide.seq + 1
+}
+
+// slideTick returns the command that draws the next frame of the slide.
+func (m Model) slideTick() tea.Cmd {
+	id, seq := m.id, m.slide.seq
+	return tea.Tick(m.SlideDuration/slideFrames, func(time.Time) tea.Msg {
+		return slideMsg{id: id, seq: seq}
+	})
+}
+
+// handleSlide moves the slide that msg is for on to its next frame.
+func (m *Model) handleSlide(msg slideMsg) tea.Cmd {
+	if msg.id != m.id || m.slide == nil || msg.seq != m.slide.seq {
+		return nil
+	}
+	s := *m.slide
+	s.frame++
+	if s.frame >= slideFrames {
+		m.slide = nil
+		return nil
+	}
+	m.slide = &s
+	return m.slideTick()
+}
+
+// slideView renders the frame of the slide between from, the view of the
+// level left, and to, the view of the level entered. The view entered comes
+// in from the right, or from the left when going back.
+func (m Model) slideView(to string) string {
+	from := m.slide.from
+	width := m.rowWidth()
+	if width <= 0 {
+		width = max(stringWidth(from), stringWidth(to))
+	}
+	off := width * (m.slide.frame + 1) / (slideFrames + 1)
+	if m.slide.back {
+		// The parent comes in from the left, pushing the submenu right.
+		off = width - off
+	}
+
+	fromLines, toLines := strings.Split(from, "\n"), strings.Split(to, "\n")
+	lines := make([]string, max(len(fromLines), len(toLines)))
+	for i := range lines {
+		var a, b string
+		if i < len(fromLines) {
+			a = pad(fromLines[i], width)
+		}
+		if i < len(toLines) {
+			b = pad(toLines[i], width)
+		}
+		if m.slide.back {
+			a, b = b, a
+		}
+		left, _ := truncate(skipCells(a, off), width-off, "")
+		right, _ := truncate(b, off, "")
+		lines[i] = pad(left, width-off) + right
+	}
+	return strings.Join(lines, "\n")
+}
+
+// pad pads s with spaces to width cells.
+func pad(s string, width int) string {
+	if n := width - stringWidth(s); n > 0 {
+		return s + strings.Repeat(" ", n)
+	}
+	return s
+}
+
+// skipCells returns s without its first n cells, keeping the escape
+// sequences styling the rest. A wide grapheme cluster cut in half is
+// replaced by spaces.
+func skipCells(s string, n int) string {
+	var (
+		b      strings.Builder
+		w      int
+		escape bool
+		runes  = []rune(s)
+		widths = runeWidths(runes)
+	)
+	for i, r := range runes {
+		if w >= n && !escape && r != ansi.Marker && widths[i] > 0 {
+			b.WriteString(string(runes[i:]))
+			break
+		}
+		switch {
+		case r == ansi.Marker:
+			escape = true
+			b.WriteRune(r)
+		case escape:
+			escape = !ansi.IsTerminator(r)
+			b.WriteRune(r)
+		case widths[i] > 0:
+			w += widths[i]
+			if w > n {
+				b.WriteString(strings.Repeat(" ", w-n))
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,58 @@
+package options
+
+import "github.com/charmbracelet/lipgloss"
+
+// rowStyle returns the style of the label of row r, resolved from the state
+// of the row: its kind, whether the cursor is on it, whether the option is
+// disabled or checked and whether the picker is blurred. The style of each
+// state inherits what it leaves unset from the more general states, down to
+// Styles.Option.
+func (m Model) rowStyle(r, cursor int) lipgloss.Style {
+	i := m.optionIndex(r)
+	item := m.item(i)
+	checked := m.SelectionMode != SelectOne && m.Checked(i)
+
+	var layers []lipgloss.Style
+	switch {
+	case item.Kind == Info:
+		layers = []lipgloss.Style{m.Styles.Info}
+	case item.Kind == Header && cursor != r:
+		layers = []lipgloss.Style{m.Styles.Header}
+	case cursor == r && checked:
+		layers = []lipgloss.Style{m.Styles.CheckedSelected, m.Styles.Selected, m.Styles.Checked}
+	case cursor == r:
+		layers = []lipgloss.Style{m.Styles.Selected}
+	case item.Disabled && checked:
+		layers = []lipgloss.Style{m.Styles.Disabled, m.Styles.Checked}
+	case item.Disabled:
+		layers = []lipgloss.Style{m.Styles.Disabled}
+	case checked:
+		layers = []lipgloss.Style{m.Styles.Checked}
+	}
+	if m.blurred && m.DimBlurred {
+		layers = append([]lipgloss.Style{m.Styles.Blurred}, layers...)
+	}
+	if len(layers) == 0 {
+		return m.styleFor(r, m.Styles.Option)
+	}
+
+	style := layers[0].Copy()
+	for _, layer := range layers[1:] {
+		style = style.Inherit(layer)
+	}
+	return m.styleFor(r, style.Inherit(m.Styles.Option))
+}
+
+// labelView renders name, a line of the label of row r, highlighting the
+// runes matched by the filter.
+func (m Model) labelView(r, cursor int, name string, matches []int) string {
+	item := m.item(m.optionIndex(r))
+	style := m.rowStyle(r, cursor)
+	switch {
+	case item.Kind == Info, item.Kind == Header && cursor != r:
+		return style.Render(name)
+	case item.Disabled:
+		return m.highlightDisabled(name, matches, style)
+	}
+	return m.highlight(name, matches, style)
+}
@@ -0,0 +1,24 @@
+package options
+
+// RenderStatic renders the picker as View does, with the cursor on the
+// option at index selected of Options and the rows width cells wide, for
+// printing a choice that's already been made, as in logs when there's no
+// terminal to run the picker in. Every option is rendered, whatever the
+// height, and the picker itself is left as it was. If the option isn't
+// shown, the cursor stays where it is. When the renderer can't show colors,
+// the output is plain, as it is for View.
+func (m Model) RenderStatic(selected, width int) string {
+	// Render a copy that doesn't share the cached view of the picker.
+	m.cache = nil
+	m.Width = max(width, 0)
+	m.Height, m.AutoHeight, m.FillHeight, m.Paginated = 0, false, false, false
+	m.slide = nil
+	m.marqueeOffset, m.marqueeWait = 0, 0
+	m.sizeWindow()
+	if r := m.rowOf(selected); r != -1 && m.selectable(r) {
+		m.selected = r
+	}
+	m.repair()
+	m.syncGrid()
+	return m.view()
+}
@@ -0,0 +1,43 @@
+package options
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const defaultStatusMessageLifetime = time.Second
+
+type statusMessageTimeoutMsg struct {
+	id  int
+	seq int
+}
+
+// NewStatusMessage shows s on a line below the options for
+// StatusMessageLifetime. The returned command clears it once the time is up,
+// unless another message has replaced it by then.
+func (m *Model) NewStatusMessage(s string) tea.Cmd {
+	m.Invalidate()
+	m.statusMessage = s
+	m.statusSeq++
+	id, seq := m.id, m.statusSeq
+	return tea.Tick(m.StatusMessageLifetime, func(time.Time) tea.Msg {
+		return statusMessageTimeoutMsg{id: id, seq: seq}
+	})
+}
+
+// handleStatusMessageTimeout clears the status message that msg is the
+// timer of, if it's still shown.
+func (m *Model) handleStatusMessageTimeout(msg statusMessageTimeoutMsg) {
+	if msg.id == m.id && msg.seq == m.statusSeq {
+		m.statusMessage = ""
+	}
+}
+
+// wholeSeconds rounds d up to a whole number of seconds, for a countdown.
+func wholeSeconds(d time.Duration) time.Duration {
+	if t := d.Truncate(time.Second); t < d {
+		return t + time.Second
+	}
+	return d
+}
@@ -0,0 +1,26 @@
+package options
+
+// stickyHeader returns the row of the group header pinned over the first row
+// of the window, which is the header of the group scrolling past it, or -1
+// if no header is pinned.
+func (m Model) stickyHeader() int {
+	if !m.StickyHeaders || m.Layout != LayoutVertical || m.Paginated || m.wrapping() || m.size <= 1 {
+		return -1
+	}
+	h := m.groupOf(m.optionIndex(m.min))
+	if h == -1 {
+		return -1
+	}
+	if r := m.rowOf(h); r != -1 && r < m.min {
+		return r
+	}
+	return -1
+}
+
+// unpinCursor scrolls the window up a row when the cursor is on the row
+// covered by the pinned header.
+func (m *Model) unpinCursor() {
+	if m.selected == m.min && m.stickyHeader() != -1 {
+		m.min--
+	}
+}
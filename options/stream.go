@@ -0,0 +1,210 @@
+package options
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// OptionsAppendedMsg is sent by the command returned by AppendOptions once
+// the options are appended. From is the index in Options of the first one
+// and Count the number appended.
+type OptionsAppendedMsg struct {
+	ID          int
+	From, Count int
+}
+
+// OptionsBatchMsg carries a batch of the options streamed by StreamOptions.
+// Err is io.EOF once the stream is done, or the error it failed with.
+type OptionsBatchMsg struct {
+	ID      int
+	Options []Option
+	Err     error
+
+	seq  int
+	next func() ([]Option, error)
+}
+
+// StreamOptions replaces the options with the ones read in batches with next
+// in the background. next returns io.EOF, along with the last batch if any,
+// once there are no more. The options can be browsed as soon as the first
+// batch lands, and later batches are added below them without moving the
+// cursor. Meanwhile the number of options loaded so far is shown in place of
+// the status bar. A load started before the stream is done supersedes it.
+//
+// Each batch is appended in one pass and handled as one message, so next
+// should read options by the hundred or more rather than one at a time,
+// which would have the program update and render the picker for each.
+func (m *Model) StreamOptions(next func() ([]Option, error)) tea.Cmd {
+	m.SetItems(nil)
+	m.loadSeq++
+	m.streaming = true
+	return tea.Batch(m.setLoading(), m.readBatch(next))
+}
+
+// StreamOptionsCtx is StreamOptions with a context, which is passed on to
+// next. Once the context is done the stream fails with its error, as
+// LoadOptionsCtx does, dropping the batch being read.
+func (m *Model) StreamOptionsCtx(ctx context.Context, next func(context.Context) ([]Option, error)) tea.Cmd {
+	return m.StreamOptions(func() ([]Option, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		items, err := next(ctx)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return items, err
+	})
+}
+
+// Streaming returns whether options are still being streamed in by
+// StreamOptions.
+func (m Model) Streaming() bool {
+	return m.streaming
+}
+
+// readBatch returns the command reading the next batch of the stream.
+func (m Model) readBatch(next func() ([]Option, error)) tea.Cmd {
+	id, seq := m.id, m.loadSeq
+	return func() tea.Msg {
+		items, err := next()
+		return OptionsBatchMsg{ID: id, Options: items, Err: err, seq: seq, next: next}
+	}
+}
+
+// handleOptionsBatch adds the options of a batch of the current stream,
+// returning the command reading the next one.
+func (m *Model) handleOptionsBatch(msg OptionsBatchMsg) tea.Cmd {
+	if msg.ID != m.id || msg.seq != m.loadSeq || !m.streaming {
+		return nil
+	}
+	if len(msg.Options) > 0 {
+		m.appendItems(msg.Options)
+	}
+	switch {
+	case errors.Is(msg.Err, io.EOF):
+		m.streaming = false
+		m.loading = false
+		return nil
+	case msg.Err != nil:
+		m.streaming = false
+		m.loadFailed(msg.Err)
+		return nil
+	}
+	return m.readBatch(msg.next)
+}
+
+// AppendOptions adds Selectable options labelled with options after the
+// current ones, keeping the cursor on the option it is on and the window
+// where it is. The whole batch is appended in one pass, so options arriving
+// from a source one at a time are best gathered and appended together. The
+// command returned sends an OptionsAppendedMsg for the ones appended, and
+// can be dropped when that isn't needed. It's nil if options is empty.
+func (m *Model) AppendOptions(options []string) tea.Cmd {
+	if len(options) == 0 {
+		return nil
+	}
+	items := make([]Option, len(options))
+	for i, o := range options {
+		items[i] = Option{Label: o}
+	}
+	from := len(m.Options)
+	m.appendItems(items)
+	msg := OptionsAppendedMsg{ID: m.id, From: from, Count: len(items)}
+	return func() tea.Msg {
+		return msg
+	}
+}
+
+// appendItems adds items after the current options, keeping the cursor on
+// the option it is on and the window where it is.
+func (m *Model) appendItems(items []Option) {
+	m.Invalidate()
+	m.loading = false
+	n := len(m.Options)
+	flat, ns := flatten(items)
+
+	// The options are shared between copies of the model, so don't append to
+	// them in place.
+	m.items = append(m.flatItems(), flat...)
+	options := make([]string, n, n+len(flat))
+	copy(options, m.Options)
+	for _, item := range flat {
+		options = append(options, item.Label)
+	}
+	m.Options = options
+	if m.nodes != nil || ns != nil {
+		m.nodes = m.appendNodes(n, ns, len(flat))
+	}
+
+	for i, item := range flat {
+		if item.Kind == Header && item.Collapsed {
+			m.setCollapsed(n+i, true)
+		}
+		if item.Checked {
+			m.SetChecked(n+i, true)
+		}
+	}
+
+	if m.filterActive() {
+		if m.FilterAsync != nil {
+			return
+		}
+		// Keep the cursor on its option among the new results.
+		cursor := m.optionIndex(m.cursorIndex())
+		top := m.min
+		m.refilter()
+		if r := m.rowOf(cursor); r != -1 {
+			m.min = top
+			m.selected = r
+			m.followCursor()
+		}
+		return
+	}
+	m.relayout()
+}
+
+// appendNodes returns the tree nodes of the n current options followed by
+// the nodes ns of count appended ones, whose parents are shifted past the
+// current options. Options without nodes are roots.
+func (m Model) appendNodes(n int, ns nodes, count int) nodes {
+	all := make(nodes, n, n+count)
+	for i := range all {
+		all[i] = node{parent: -1}
+	}
+	copy(all, m.nodes)
+	for i := 0; i < count; i++ {
+		nd := node{parent: -1}
+		if ns != nil {
+			nd = ns[i]
+			if nd.parent != -1 {
+				nd.parent += n
+			}
+		}
+		all = append(all, nd)
+	}
+	return all
+}
+
+// streamingView renders the number of options loaded so far by
+// StreamOptions.
+func (m Model) streamingView() string {
+	msg := fmt.Sprintf(m.StreamingMessage, groupDigits(len(m.Options)))
+	return m.Styles.StatusBar.Render(m.Spinner.View() + " " + msg)
+}
+
+// groupDigits formats n with its digits grouped in threes, as in 1,240.
+func groupDigits(n int) string {
+	s := strconv.Itoa(n)
+	start := (len(s)-1)%3 + 1
+	out := s[:start]
+	for i := start; i < len(s); i += 3 {
+		out += "," + s[i:i+3]
+	}
+	return out
+}
@@ -0,0 +1,49 @@
+package options
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAppendOptions(t *testing.T) {
+	m := newTestModel(WithOptions(numbered(20)))
+	resize(&m, 20, 5)
+	press(&m, times(12, "down")...)
+	before, index := m.min, m.Index()
+
+	cmd := m.AppendOptions([]string{"x", "y"})
+	if len(m.Options) != 22 || m.Options[20] != "x" || m.Options[21] != "y" {
+		t.Fatalf("options %q", m.Options[18:])
+	}
+	if m.min != before || m.Index() != index {
+		t.Errorf("window at %d, cursor on %d, want %d and %d", m.min, m.Index(), before, index)
+	}
+	msg, ok := cmd().(OptionsAppendedMsg)
+	if !ok || msg.ID != m.ID() || msg.From != 20 || msg.Count != 2 {
+		t.Errorf("message %#v", msg)
+	}
+	press(&m, times(20, "down")...)
+	if got := cursorLabel(t, m); got != "y" {
+		t.Errorf("cursor on %q at the end, want y", got)
+	}
+	if cmd := m.AppendOptions(nil); cmd != nil || len(m.Options) != 22 {
+		t.Errorf("appending none: %d options, command %v", len(m.Options), cmd)
+	}
+}
+
+func TestAppendOptionsFiltered(t *testing.T) {
+	m := newTestModel(WithOptions([]string{"apple", "banana"}))
+	m.SetFilterMode(FilterModeSubstring)
+	m.SetFilterText("an")
+	m.AppendOptions([]string{"mango", "cherry"})
+	if got, want := m.VisibleOptions(), []string{"banana", "mango"}; !slices.Equal(got, want) {
+		t.Errorf("VisibleOptions() = %q, want %q", got, want)
+	}
+
+	// Options appended after a copy is taken aren't added to it.
+	c := m
+	m.AppendOptions([]string{"pecan"})
+	if len(c.Options) != 4 || len(c.VisibleOptions()) != 2 {
+		t.Errorf("copy has %d options, %d shown", len(c.Options), len(c.VisibleOptions()))
+	}
+}
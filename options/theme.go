@@ -0,0 +1,139 @@
+package options
+
+import "github.com/charmbracelet/lipgloss"
+
+// palette is the set of colors a theme styles the picker with.
+type palette struct {
+	title, titleBackground lipgloss.TerminalColor
+
+	accent   lipgloss.TerminalColor
+	header   lipgloss.TerminalColor
+	error    lipgloss.TerminalColor
+	checked  lipgloss.TerminalColor
+	dim      lipgloss.TerminalColor
+	disabled lipgloss.TerminalColor
+	subtle   lipgloss.TerminalColor
+	faint    lipgloss.TerminalColor
+}
+
+// defaultPalette is the palette of the default styles.
+var defaultPalette = palette{
+	title:           lipgloss.Color("230"),
+	titleBackground: lipgloss.Color("62"),
+	accent:          accentColor,
+	header:          headerColor,
+	error:           errorColor,
+	checked:         checkedColor,
+	dim:             dimColor,
+	disabled:        disabledColor,
+	subtle:          subtleColor,
+	faint:           faintColor,
+}
+
+// base16Palette takes its colors from the 16 of the terminal's own color
+// scheme, so that they match whatever it is.
+var base16Palette = palette{
+	title:           lipgloss.Color("15"),
+	titleBackground: lipgloss.Color("4"),
+	accent:          lipgloss.Color("6"),
+	header:          lipgloss.Color("4"),
+	error:           lipgloss.Color("1"),
+	checked:         lipgloss.Color("2"),
+	dim:             lipgloss.Color("7"),
+	disabled:        lipgloss.Color("8"),
+	subtle:          lipgloss.Color("8"),
+	faint:           lipgloss.Color("8"),
+}
+
+// ThemeCharm returns the default styles of the picker, in pinks and purples
+// that adapt to the background the renderer detects.
+func ThemeCharm(r *lipgloss.Renderer) Styles {
+	return paletteStyles(r, defaultPalette)
+}
+
+// ThemeBase16 returns styles drawn in the 16 colors of the terminal's color
+// scheme, which follow the scheme when it changes.
+func ThemeBase16(r *lipgloss.Renderer) Styles {
+	return paletteStyles(r, base16Palette)
+}
+
+// ThemeMonochrome returns styles without any colors, which tell the parts
+// of the picker apart with bold, faint, underlined and reversed text only.
+func ThemeMonochrome(r *lipgloss.Renderer) Styles {
+	none := lipgloss.NoColor{}
+	s := paletteStyles(r, palette{
+		title: none, titleBackground: none,
+		accent: none, header: none, error: none, checked: none,
+		dim: none, disabled: none, subtle: none, faint: none,
+	})
+	s.Title = s.Title.Reverse(true)
+	s.DisabledCursor = s.DisabledCursor.Faint(true)
+	s.Blurred = s.Blurred.Faint(true)
+	s.Cursor = s.Cursor.Bold(true)
+	s.Disabled = s.Disabled.Faint(true)
+	s.Checked = s.Checked.Bold(true)
+	s.Info = s.Info.Faint(true)
+	s.Header = s.Header.Underline(true)
+	s.FilterPending = s.FilterPending.Faint(true)
+	s.FilterError = s.FilterError.Bold(true)
+	s.FilterCount = s.FilterCount.Faint(true)
+	s.FilterMode = s.FilterMode.Faint(true)
+	s.NoMatches = s.NoMatches.Faint(true)
+	s.ScrollbarTrack = s.ScrollbarTrack.Faint(true)
+	s.Overflow = s.Overflow.Faint(true)
+	s.Number = s.Number.Faint(true)
+	s.StatusBar = s.StatusBar.Faint(true)
+	s.StatusMessage = s.StatusMessage.Bold(true)
+	s.Description = s.Description.Faint(true)
+	s.Secondary = s.Secondary.Faint(true)
+	s.Shortcut = s.Shortcut.Faint(true)
+	s.ColumnHeader = s.ColumnHeader.Underline(true)
+	s.InactivePaginationDot = s.InactivePaginationDot.Faint(true)
+	s.Empty = s.Empty.Faint(true)
+	s.Loading = s.Loading.Faint(true)
+	s.Error = s.Error.Bold(true)
+	return s
+}
+
+// paletteStyles returns the styles of the picker drawn in the colors of p.
+func paletteStyles(r *lipgloss.Renderer, p palette) Styles {
+	return Styles{
+		Title:          r.NewStyle().Background(p.titleBackground).Foreground(p.title).Padding(0, 1),
+		Border:         r.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(p.faint),
+		DisabledCursor: r.NewStyle().Foreground(p.dim),
+		Cursor:         r.NewStyle().Foreground(p.accent),
+		Blurred:        r.NewStyle().Foreground(p.dim),
+		Option:         r.NewStyle(),
+		Selected:       r.NewStyle().Foreground(p.accent).Bold(true),
+		Disabled:       r.NewStyle().Foreground(p.disabled),
+		Checked:        r.NewStyle().Foreground(p.checked),
+		Info:           r.NewStyle().Foreground(p.subtle),
+		Header:         r.NewStyle().Foreground(p.header).Bold(true),
+		FilterPrompt:   r.NewStyle().Foreground(p.accent),
+		FilterCursor:   r.NewStyle().Foreground(p.accent),
+		FilterMatch:    r.NewStyle().Underline(true),
+		NoMatches:      r.NewStyle().Foreground(p.faint).PaddingLeft(paddingLeft),
+		FilterPending:  r.NewStyle().Foreground(p.faint),
+		FilterError:    r.NewStyle().Foreground(p.error),
+		FilterCount:    r.NewStyle().Foreground(p.subtle),
+		FilterMode:     r.NewStyle().Foreground(p.subtle),
+		ScrollbarThumb: r.NewStyle().Foreground(p.accent),
+		ScrollbarTrack: r.NewStyle().Foreground(p.faint),
+		Overflow:       r.NewStyle().Foreground(p.faint).PaddingLeft(paddingLeft),
+		Number:         r.NewStyle().Foreground(p.subtle),
+		StatusBar:      r.NewStyle().Foreground(p.faint).PaddingLeft(paddingLeft),
+		StatusMessage:  r.NewStyle().Foreground(p.accent).PaddingLeft(paddingLeft),
+		Description:    r.NewStyle().Foreground(p.subtle).PaddingLeft(paddingLeft),
+		Secondary:      r.NewStyle().Foreground(p.subtle),
+		Shortcut:       r.NewStyle().Foreground(p.faint),
+		ColumnHeader:   r.NewStyle().Foreground(p.header).Bold(true),
+
+		Pagination:            r.NewStyle().PaddingLeft(paddingLeft),
+		ActivePaginationDot:   r.NewStyle().Foreground(p.accent).SetString("•"),
+		InactivePaginationDot: r.NewStyle().Foreground(p.faint).SetString("•"),
+
+		Empty:   r.NewStyle().Foreground(p.faint).PaddingLeft(paddingLeft),
+		Loading: r.NewStyle().Foreground(p.subtle).PaddingLeft(paddingLeft),
+		Error:   r.NewStyle().Foreground(p.error).PaddingLeft(paddingLeft),
+	}
+}
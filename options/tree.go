@@ -0,0 +1,166 @@
+package options
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	treeIndent    = "  "
+	collapsedMark = "▸"
+	expandedMark  = "▾"
+)
+
+// node holds the position of a flattened option within the tree.
+type node struct {
+	depth    int
+	parent   int
+	branch   bool
+	expanded bool
+}
+
+type nodes []node
+
+// flatten lays the given options out depth-first. The returned nodes are nil
+// when none of the options have children, which keeps the picker flat.
+func flatten(items []Option) ([]Option, nodes) {
+	tree := false
+	for _, item := range items {
+		tree = tree || len(item.Children) > 0
+	}
+	if !tree {
+		flat := make([]Option, len(items))
+		copy(flat, items)
+		return flat, nil
+	}
+
+	var (
+		flat = make([]Option, 0, len(items))
+		ns   = make(nodes, 0, len(items))
+	)
+	var walk func(items []Option, depth, parent int)
+	walk = func(items []Option, depth, parent int) {
+		for _, item := range items {
+			i := len(flat)
+			children := item.Children
+			item.Children = nil
+			flat = append(flat, item)
+			ns = append(ns, node{
+				depth:    depth,
+				parent:   parent,
+				branch:   len(children) > 0,
+				expanded: item.Expanded,
+			})
+			if len(children) > 0 {
+				walk(children, depth+1, i)
+			}
+		}
+	}
+	walk(items, 0, -1)
+	return flat, ns
+}
+
+// unflatten nests the flat list of options back under their parents.
+func (ns nodes) unflatten(flat []Option) []Option {
+	var build func(parent int) []Option
+	build = func(parent int) []Option {
+		var items []Option
+		for i, n := range ns {
+			if n.parent != parent {
+				continue
+			}
+			item := flat[i]
+			item.Expanded = n.expanded
+			if n.branch {
+				item.Children = build(i)
+			}
+			items = append(items, item)
+		}
+		return items
+	}
+	return build(-1)
+}
+
+// tree returns whether the picker is in tree mode.
+func (m Model) tree() bool {
+	return m.nodes != nil && len(m.nodes) == len(m.Options)
+}
+
+// Path returns the labels from the root of the tree down to the option at
+// index i of Options.
+func (m Model) Path(i int) []string {
+	if i < 0 || i >= len(m.Options) {
+		return nil
+	}
+	if !m.tree() {
+		return []string{m.Options[i]}
+	}
+	var path []string
+	for ; i != -1; i = m.nodes[i].parent {
+		path = append([]string{m.Options[i]}, path...)
+	}
+	return path
+}
+
+// DidSelectPath returns whether a user has selected an option (on this msg)
+// along with the path from the root of the tree to that option.
+func (m Model) DidSelectPath(msg tea.Msg) (bool, []string) {
+	didSelect, i := m.didSelectIndex(msg)
+	if !didSelect {
+		return false, nil
+	}
+	return true, m.Path(i)
+}
+
+// branch returns whether the option at index i has children.
+func (m Model) branch(i int) bool {
+	return m.tree() && i >= 0 && m.nodes[i].branch
+}
+
+// treePrefix returns the indentation and expand marker for the option at
+// index i of Options.
+func (m Model) treePrefix(i int) string {
+	if !m.tree() || m.filterActive() {
+		return ""
+	}
+	n := m.nodes[i]
+	mark := " "
+	if n.branch {
+		mark = collapsedMark
+		if n.expanded {
+			mark = expandedMark
+		}
+	}
+	return strings.Repeat(treeIndent, n.depth) + mark + " "
+}
+
+// expand expands or collapses the option on the cursor. Collapsing an option
+// that is already collapsed, or a leaf, moves the cursor to its parent.
+func (m *Model) expand(v bool) {
+	i := m.optionIndex(m.cursorIndex())
+	if !m.tree() || i == -1 {
+		return
+	}
+	n := m.nodes[i]
+	if n.branch && n.expanded != v {
+		m.setExpanded(i, v)
+		return
+	}
+	// The parent isn't shown while the filter leaves it out.
+	if r := m.rowOf(n.parent); !v && n.parent != -1 && r != -1 {
+		m.selected = r
+		m.followCursor()
+	}
+}
+
+// setExpanded expands or collapses the option at index i.
+func (m *Model) setExpanded(i int, v bool) {
+	// Nodes are shared between copies of the model, so don't modify them in
+	// place.
+	ns := make(nodes, len(m.nodes))
+	copy(ns, m.nodes)
+	ns[i].expanded = v
+	m.nodes = ns
+	m.relayout()
+}
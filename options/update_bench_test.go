@@ -0,0 +1,164 @@
+package options
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// navigationKeys are the keys held down to move through a list, as key
+// repeat sends them.
+var navigationKeys = []tea.Msg{keyMsg("down"), keyMsg("up"), keyMsg("pgdown"), keyMsg("pgup")}
+
+// benchModel returns a picker of n options, in a window of 30 lines, with
+// the cursor halfway down the list.
+func benchModel(n int) Model {
+	m := newTestModel(WithOptions(numbered(n)))
+	resize(&m, 80, 32)
+	m.selected = n / 2
+	m.followCursor()
+	return m
+}
+
+func BenchmarkUpdateNavigation(b *testing.B) {
+	for _, n := range []int{10_000, 100_000} {
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			m := benchModel(n)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				m, _ = m.Update(navigationKeys[i%len(navigationKeys)])
+			}
+		})
+	}
+}
+
+func TestUpdateNavigationAllocs(t *testing.T) {
+	for _, n := range []int{10_000, 100_000} {
+		m := benchModel(n)
+		for _, msg := range navigationKeys {
+			if allocs := testing.AllocsPerRun(100, func() { m, _ = m.Update(msg) }); allocs != 0 {
+				t.Errorf("%v on %d options makes %v allocations, want none", msg, n, allocs)
+			}
+		}
+	}
+}
+
+// BenchmarkUpdateInPlace compares updating a picker of 50k options held by
+// value, with Update, and by pointer, with UpdateInPlace.
+func BenchmarkUpdateInPlace(b *testing.B) {
+	b.Run("Update", func(b *testing.B) {
+		m := benchModel(50_000)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			m, _ = m.Update(navigationKeys[i%2])
+		}
+	})
+	b.Run("UpdateInPlace", func(b *testing.B) {
+		m := benchModel(50_000)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			m.UpdateInPlace(navigationKeys[i%2])
+		}
+	})
+}
+
+func TestUpdateInPlaceMatchesUpdate(t *testing.T) {
+	byValue, byPointer := benchModel(50_000), benchModel(50_000)
+	for i := 0; i < 100; i++ {
+		msg := navigationKeys[i%len(navigationKeys)]
+		byValue, _ = byValue.Update(msg)
+		byPointer.UpdateInPlace(msg)
+		if byValue.selected != byPointer.selected || byValue.min != byPointer.min {
+			t.Fatalf("after %v, Update left the cursor on %d and the window at %d, UpdateInPlace on %d and at %d",
+				msg, byValue.selected, byValue.min, byPointer.selected, byPointer.min)
+		}
+	}
+}
+
+// BenchmarkUpdateThroughput sends a mix of key presses to a picker of 100k
+// options checking them, keeping the model each update returns as a program
+// does.
+func BenchmarkUpdateThroughput(b *testing.B) {
+	m := benchModel(100_000)
+	m.SelectionMode = SelectMany
+	msgs := []tea.Msg{keyMsg("down"), keyMsg(" "), keyMsg("down"), keyMsg("up"), keyMsg("pgdown"), keyMsg("pgup")}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m, _ = m.Update(msgs[i%len(msgs)])
+	}
+}
+
+func TestCopiesShareNothingChanged(t *testing.T) {
+	m := newTestModel(WithOptions(numbered(5)))
+	m.SelectionMode = SelectMany
+	resize(&m, 20, 10)
+	c := m
+	c.SetChecked(1, true)
+	c.SetOptions(numbered(3))
+	// Styles of this version of Lip Gloss share their rules until copied.
+	c.Styles.Selected = c.Styles.Selected.Copy().Underline(true)
+	press(&c, "down", "down")
+
+	if m.Checked(1) {
+		t.Error("checking an option on a copy checked it on the original")
+	}
+	if len(m.Options) != 5 || m.Options[4] != "o4" {
+		t.Errorf("setting the options of a copy changed the original's to %q", m.Options)
+	}
+	if m.Styles.Selected.GetUnderline() {
+		t.Error("changing a style of a copy changed the original's")
+	}
+	if m.selected != 0 {
+		t.Errorf("moving the cursor of a copy moved the original's to %d", m.selected)
+	}
+}
+
+func TestCheckedAcrossBlocks(t *testing.T) {
+	m := newTestModel(WithOptions(numbered(3000)))
+	m.SelectionMode = SelectMany
+	for _, i := range []int{5, checkBlock - 1, checkBlock, 2999} {
+		m.SetChecked(i, true)
+	}
+	c := m
+	c.SetChecked(checkBlock, false)
+	if got, want := m.CheckedIndexes(), []int{5, checkBlock - 1, checkBlock, 2999}; !slices.Equal(got, want) {
+		t.Errorf("checked %v, want %v", got, want)
+	}
+	if got, want := c.CheckedIndexes(), []int{5, checkBlock - 1, 2999}; !slices.Equal(got, want) {
+		t.Errorf("checked %v on the copy, want %v", got, want)
+	}
+
+	c.SelectionMode = SelectRadio
+	c.SetChecked(2000, true)
+	if got, want := c.CheckedIndexes(), []int{2000}; !slices.Equal(got, want) {
+		t.Errorf("checked %v in the SelectRadio mode, want %v", got, want)
+	}
+	if len(m.CheckedIndexes()) != 4 {
+		t.Errorf("checking a radio option on a copy left the original with %v", m.CheckedIndexes())
+	}
+}
+
+// BenchmarkResizeBurst sends a burst of resizes to a grid of 100k options
+// with a status bar, as dragging the corner of a terminal does, followed by
+// a key press.
+func BenchmarkResizeBurst(b *testing.B) {
+	m := benchModel(100_000)
+	m.Layout = LayoutGrid
+	m.ShowStatusBar = true
+	m.Invalidate()
+	m.UpdateInPlace(nil)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for w := 60; w < 80; w++ {
+			m.UpdateInPlace(tea.WindowSizeMsg{Width: w, Height: 32})
+		}
+		m.UpdateInPlace(navigationKeys[i%2])
+	}
+}
@@ -0,0 +1,98 @@
+package options
+
+import "testing"
+
+// BenchmarkView renders a 60-line window of 500 numbered options, moving the
+// cursor between frames so that none is served from the cache.
+func BenchmarkView(b *testing.B) {
+	m := newTestModel(WithOptions(numbered(500)))
+	m.ShowNumbers = true
+	m.ShowOverflowHints = true
+	resize(&m, 80, 64)
+	down, up := keyMsg("down"), keyMsg("up")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		msg := down
+		if i%2 == 1 {
+			msg = up
+		}
+		m.UpdateInPlace(msg)
+		_ = m.View()
+	}
+}
+
+// BenchmarkViewOffset renders a 30-line window at the top and near the end
+// of 300k options, which should cost the same.
+func BenchmarkViewOffset(b *testing.B) {
+	options := numbered(300_000)
+	for _, bm := range []struct {
+		name   string
+		offset int
+	}{
+		{"top", 0},
+		{"end", len(options) - 10},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			m := newTestModel(WithOptions(options))
+			resize(&m, 80, 32)
+			m.selected = bm.offset
+			m.followCursor()
+			down, up := keyMsg("down"), keyMsg("up")
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				msg := down
+				if i%2 == 1 {
+					msg = up
+				}
+				m.UpdateInPlace(msg)
+				_ = m.View()
+			}
+		})
+	}
+}
+
+// BenchmarkSetOptions sets 200k options and renders the first frame, which
+// should cost no more than copying them.
+func BenchmarkSetOptions(b *testing.B) {
+	options := numbered(200_000)
+	m := newTestModel()
+	resize(&m, 80, 32)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.SetOptions(options)
+		_ = m.View()
+	}
+}
+
+// BenchmarkCopyOptions copies 200k options, for BenchmarkSetOptions to be
+// compared with.
+func BenchmarkCopyOptions(b *testing.B) {
+	options := numbered(200_000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = append([]string(nil), options...)
+	}
+}
+
+// BenchmarkViewGrid renders a grid of 5k options fitting as many columns as
+// the width allows, moving the cursor between frames.
+func BenchmarkViewGrid(b *testing.B) {
+	m := newTestModel(WithOptions(numbered(5000)))
+	m.Layout = LayoutGrid
+	resize(&m, 80, 24)
+	down, up := keyMsg("down"), keyMsg("up")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		msg := down
+		if i%2 == 1 {
+			msg = up
+		}
+		m.UpdateInPlace(msg)
+		_ = m.View()
+	}
+}
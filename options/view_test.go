@@ -0,0 +1,109 @@
+package options_test
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/RemiG26/bubbles/options"
+	"github.com/RemiG26/bubbles/options/optionstest"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var fruit = []string{"Apples", "Bananas", "Cherries", "Dates", "Elderberries", "Figs", "Grapes"}
+
+func TestViews(t *testing.T) {
+	tests := []struct {
+		name  string
+		width int
+		setup func(m *options.Model)
+		keys  []string
+	}{
+		{name: "initial"},
+		{name: "moved", keys: []string{"down", "down"}},
+		{name: "scrolled", keys: []string{"down", "down", "down", "down", "down", "down"}},
+		{name: "filtering", keys: []string{"/", "e", "r"}},
+		{name: "filtered", keys: []string{"/", "e", "r", "enter", "down"}},
+		{name: "no_matches", keys: []string{"/", "x", "y", "z"}},
+		{
+			name:  "checked",
+			setup: func(m *options.Model) { m.SelectionMode = options.SelectMany },
+			keys:  []string{" ", "down", "down", " "},
+		},
+		{
+			name:  "grid",
+			width: 40,
+			setup: func(m *options.Model) {
+				m.Layout = options.LayoutGrid
+				m.Columns = 3
+			},
+			keys: []string{"right", "down"},
+		},
+		{
+			name: "bordered",
+			setup: func(m *options.Model) {
+				m.Title = "Fruit"
+				m.Bordered = true
+				m.ShowStatusBar = true
+			},
+			keys: []string{"down"},
+		},
+		{
+			name: "paginated",
+			setup: func(m *options.Model) {
+				m.Paginated = true
+				m.ShowNumbers = true
+			},
+			keys: []string{"pgdown"},
+		},
+		{
+			name: "numbered",
+			setup: func(m *options.Model) {
+				m.ShowNumbers = true
+				m.ShowOverflowHints = true
+			},
+			keys: []string{"down", "down", "down", "down"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := optionstest.New(options.WithOptions(fruit))
+			if tt.setup != nil {
+				tt.setup(&m)
+			}
+			width := tt.width
+			if width == 0 {
+				width = 24
+			}
+			optionstest.Send(&m, tea.WindowSizeMsg{Width: width, Height: 6})
+			optionstest.Type(&m, tt.keys...)
+			optionstest.Golden(t, filepath.Join("testdata", tt.name+".golden"), m.View())
+		})
+	}
+}
+
+func TestSendSelects(t *testing.T) {
+	m := optionstest.New(options.WithOptions(fruit))
+	run := optionstest.Type(&m, "down", "enter", "down", "enter")
+	if want := []string{"Bananas", "Cherries"}; !slices.Equal(run.Selected, want) {
+		t.Errorf("selected %q, want %q", run.Selected, want)
+	}
+	if run.Quit {
+		t.Error("quit without QuitOnSelect")
+	}
+}
+
+func TestSendQuitsOnSelect(t *testing.T) {
+	m := optionstest.New(options.WithOptions(fruit))
+	m.QuitOnSelect = true
+	run := optionstest.Type(&m, "enter")
+	if !run.Quit {
+		t.Fatal("didn't quit on selecting with QuitOnSelect")
+	}
+	if want := []string{"Apples"}; !slices.Equal(run.Selected, want) {
+		t.Errorf("selected %q, want %q", run.Selected, want)
+	}
+	if !m.WasSubmitted() {
+		t.Error("not submitted after selecting")
+	}
+}
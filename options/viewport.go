@@ -0,0 +1,217 @@
+package options
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// WindowChangedMsg is sent by Update when NotifyWindowChanges is set and the
+// options shown have changed since the last one. Min and Max are the first
+// and last rows shown out of Total, as returned by VisibleRange.
+type WindowChangedMsg struct {
+	ID       int
+	Min, Max int
+	Total    int
+}
+
+// EnsureVisible scrolls the window by as little as it takes for the option
+// at index i of Options to be shown, or to the page it's on when paginated.
+// It does nothing if the option is hidden, by the filter or in a collapsed
+// group. The cursor stays where it is unless DragCursorOnScroll is set.
+func (m *Model) EnsureVisible(i int) {
+	m.Invalidate()
+	r := m.rowOf(i)
+	if r == -1 {
+		return
+	}
+	selected, behavior := m.selected, m.ScrollBehavior
+	m.selected, m.ScrollBehavior = r, ScrollEdge
+	m.followCursor()
+	m.selected, m.ScrollBehavior = selected, behavior
+	m.dragCursor()
+}
+
+// ScrollTo scrolls the window so that it starts at the line top, without
+// scrolling past either end of the list. When paginated, it shows the page
+// that line is on. The cursor stays where it is unless DragCursorOnScroll
+// is set.
+func (m *Model) ScrollTo(top int) {
+	m.Invalidate()
+	size := m.size
+	if m.Paginated {
+		size = m.pageSize()
+		top = max(min(top, m.lineCount()-1), 0) / size * size
+	} else {
+		top = max(min(top, m.lineCount()-size), 0)
+	}
+	m.min, m.size = top, size
+	m.dragCursor()
+}
+
+// VisibleRange returns the first and last rows shown, counted among the rows
+// that the filter, collapsed groups and collapsed parents leave, or -1 and
+// -1 when no options are shown. In LayoutGrid they're the first and last
+// lines of the grid shown instead.
+func (m Model) VisibleRange() (first, last int) {
+	if m.loading || m.rowCount() == 0 {
+		return -1, -1
+	}
+	switch m.Layout {
+	case LayoutHorizontal:
+		_, start, end := m.horizontalRun()
+		return start, end - 1
+	case LayoutGrid:
+		_, rows := m.gridSize()
+		return m.min, min(m.min+m.size-1, rows-1)
+	}
+	return m.min, min(m.lastVisible(), m.rowCount()-1)
+}
+
+// VisibleOptions returns the options shown, in the order they're rendered
+// in, with a sticky header in place of the first row it covers. Each is
+// shown on a row of its own, or a cell of the grid, read line by line.
+func (m Model) VisibleOptions() []string {
+	first, last := m.VisibleRange()
+	if first == -1 {
+		return nil
+	}
+	var options []string
+	switch m.Layout {
+	case LayoutGrid:
+		cols, rows := m.gridSize()
+		for line := first; line <= last; line++ {
+			for col := 0; col < cols; col++ {
+				if i := m.optionIndex(col*rows + line); i != -1 {
+					options = append(options, m.Options[i])
+				}
+			}
+		}
+		return options
+	}
+
+	for r := first; r <= last; r++ {
+		shown := r
+		if sticky := m.stickyHeader(); r == first && sticky != -1 {
+			// The header of the group scrolling past covers its first row.
+			shown = sticky
+		}
+		if i := m.optionIndex(shown); i != -1 {
+			options = append(options, m.Options[i])
+		}
+	}
+	return options
+}
+
+// ViewportRange returns the first and last lines of the window the options
+// are scrolled to, which holds Height lines less the ones taken up by the
+// filter, overflow hints, description and page indicator. The last line may
+// be past the end of the list when it doesn't fill the window. With no
+// Height the window holds the whole list. In LayoutHorizontal it's the same
+// as VisibleRange.
+func (m Model) ViewportRange() (first, last int) {
+	switch {
+	case m.Layout == LayoutHorizontal:
+		return m.VisibleRange()
+	case m.Height <= 0:
+		return 0, m.lineCount() - 1
+	}
+	return m.min, m.min + m.size - 1
+}
+
+// VisibleCount returns the number of options shown.
+func (m Model) VisibleCount() int {
+	return len(m.VisibleOptions())
+}
+
+// ScrollPercent returns how far the options are scrolled, from 0 at the top
+// of the list to 1 at the bottom. It's 0 when they all fit.
+func (m Model) ScrollPercent() float64 {
+	first, last := m.VisibleRange()
+	if first == -1 {
+		return 0
+	}
+	total := m.rowCount()
+	if m.Layout == LayoutGrid {
+		total = m.lineCount()
+	}
+	hidden := total - (last - first + 1)
+	if hidden <= 0 {
+		return 0
+	}
+	return min(float64(first)/float64(hidden), 1)
+}
+
+// windowMax returns the last line of the window, or the last line of the
+// list when the window reaches past its end.
+func (m Model) windowMax() int {
+	return max(min(m.min+m.size-1, m.lineCount()-1), m.min)
+}
+
+// inWindow returns whether row r is shown in the window. A row taller than
+// the window is shown, cut short, when it's at the top of it.
+func (m Model) inWindow(r int) bool {
+	if !m.wrapping() || m.Paginated {
+		line := m.line(r)
+		return line >= m.min && line < m.min+m.size
+	}
+	if r < m.min {
+		return false
+	}
+	lines := 0
+	for j := m.min; j <= r; j++ {
+		lines += m.rowHeight(j)
+	}
+	return lines <= m.size || r == m.min
+}
+
+// ensureCursorVisible scrolls the window to the row the cursor is on if a
+// change to the rows, other than scrolling, has left it out of the window.
+func (m *Model) ensureCursorVisible() {
+	if r := m.cursorIndex(); r != -1 && !m.inWindow(r) {
+		m.selected = r
+		m.followCursor()
+	}
+}
+
+// dragCursor moves the cursor onto the selectable row in the window closest
+// to it, if DragCursorOnScroll is set and the window has left it behind.
+func (m *Model) dragCursor() {
+	cursor := m.cursorIndex()
+	if !m.DragCursorOnScroll || cursor == -1 || m.inWindow(cursor) {
+		return
+	}
+	closest := -1
+	for r := 0; r < m.rowCount(); r++ {
+		if !m.selectable(r) || !m.inWindow(r) {
+			continue
+		}
+		if closest == -1 || abs(r-cursor) < abs(closest-cursor) {
+			closest = r
+		}
+	}
+	if closest != -1 {
+		m.selected = closest
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// windowChanged returns the command sending a WindowChangedMsg if the
+// options shown have changed since the last one was sent, or nil.
+func (m *Model) windowChanged() tea.Cmd {
+	first, last := m.VisibleRange()
+	total := m.rowCount()
+	if m.Layout == LayoutGrid {
+		total = m.lineCount()
+	}
+	msg := WindowChangedMsg{ID: m.id, Min: first, Max: last, Total: total}
+	if msg == m.window {
+		return nil
+	}
+	m.window = msg
+	return func() tea.Msg {
+		return msg
+	}
+}
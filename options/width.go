@@ -0,0 +1,72 @@
+package options
+
+import (
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/muesli/reflow/ansi"
+	"github.com/rivo/uniseg"
+)
+
+// stringWidth returns the number of cells the widest line of s takes up in
+// the terminal. Escape sequences take up no room, and a grapheme cluster, such
+// as an emoji made of several runes joined together, is measured as a whole.
+func stringWidth(s string) int {
+	var width int
+	for {
+		line, rest, more := strings.Cut(s, "\n")
+		width = max(width, lineWidth(line))
+		if !more {
+			return width
+		}
+		s = rest
+	}
+}
+
+// lineWidth returns the number of cells line takes up. Printable ASCII, which
+// most labels are made of, takes up a cell a byte and is measured without
+// breaking it into grapheme clusters.
+func lineWidth(line string) int {
+	for i := 0; i < len(line); i++ {
+		if c := line[i]; c < ' ' || c > '~' {
+			w := 0
+			for _, rw := range runeWidths([]rune(line)) {
+				w += rw
+			}
+			return w
+		}
+	}
+	return len(line)
+}
+
+// runeWidths returns the number of cells each of runes takes up. The whole
+// width of a grapheme cluster is given to its first rune and the others get
+// none, so that a cut after a rune of width 0 never splits a cluster. Runes
+// of escape sequences get none either.
+func runeWidths(runes []rune) []int {
+	widths := make([]int, len(runes))
+	var (
+		text   []rune
+		at     []int
+		escape bool
+	)
+	for i, r := range runes {
+		switch {
+		case r == ansi.Marker:
+			escape = true
+		case escape:
+			escape = !ansi.IsTerminator(r)
+		default:
+			text = append(text, r)
+			at = append(at, i)
+		}
+	}
+
+	g := uniseg.NewGraphemes(string(text))
+	for k := 0; g.Next(); {
+		cluster := g.Runes()
+		widths[at[k]] = runewidth.StringWidth(string(cluster))
+		k += len(cluster)
+	}
+	return widths
+}
@@ -0,0 +1,93 @@
+package options
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// brokenEscape matches an escape sequence cut before its final byte.
+var brokenEscape = regexp.MustCompile("\x1b\\[[0-9;]*$|\x1b\\[[0-9;]*[^0-9;m]")
+
+func TestTruncateExactWidth(t *testing.T) {
+	labels := []string{
+		"plain ascii label that is long",
+		"\x1b[31mred\x1b[0m and \x1b[1;32mbold green\x1b[0m text",
+		"emoji 😀😀😀😀😀😀😀😀😀",
+		"👩‍👩‍👧‍👦 family 👩‍👩‍👧‍👦 family 👩‍👩‍👧‍👦",
+		"flags 🇫🇷🇩🇪🇯🇵🇺🇸🇧🇷",
+		"combining éééééééé",
+		"漢字の長いラベルです漢字の長いラベル",
+		"\x1b[35m漢字\x1b[0m😀é mixed \x1b[4mall\x1b[0m of them",
+	}
+	for _, label := range labels {
+		for width := 1; width < stringWidth(label); width++ {
+			got, _ := truncate(label, width, "…")
+			if w := stringWidth(got); w != width {
+				t.Errorf("truncate(%q, %d) = %q, %d cells wide", label, width, got, w)
+			}
+			if brokenEscape.MatchString(got) {
+				t.Errorf("truncate(%q, %d) = %q cuts an escape sequence", label, width, got)
+			}
+			if !strings.HasSuffix(strings.TrimRight(stripEscapes(got), " "), "…") {
+				t.Errorf("truncate(%q, %d) = %q doesn't end with the ellipsis", label, width, got)
+			}
+		}
+		if got, _ := truncate(label, stringWidth(label), "…"); got != label {
+			t.Errorf("truncate(%q) to its own width = %q", label, got)
+		}
+	}
+}
+
+func TestViewTruncatesToWidth(t *testing.T) {
+	m := newTestModel(WithOptions([]string{
+		"\x1b[31mred label long enough to be cut\x1b[0m",
+		"😀😀😀😀😀😀😀😀😀😀😀😀😀😀😀",
+		"éééééééééééééé",
+		"漢字の長いラベルです漢字の長いラベル",
+	}))
+	for _, width := range []int{9, 10, 11, 12} {
+		resize(&m, width, 10)
+		for _, line := range strings.Split(m.View(), "\n") {
+			if w := stringWidth(line); w != width {
+				t.Errorf("%q is %d cells wide at width %d", line, w, width)
+			}
+		}
+	}
+}
+
+func TestRowWidthsMatrix(t *testing.T) {
+	tricky := []string{
+		"ascii only, long enough to be cut",
+		"😀 emoji 😀 emoji 😀 emoji 😀 emoji",
+		"👩‍👩‍👧‍👦 joined 👨‍💻 emoji 🧑🏽‍🚀 and more",
+		"🇫🇷🇩🇪🇯🇵🇺🇸🇧🇷🇮🇳 flags and more flags",
+		"ééé combining ñññ marks ééé ñññ",
+		"漢字とかなの混ざった長いラベルです",
+		"\x1b[1mbold\x1b[0m 漢字 😀 é\x1b[32m green\x1b[0m mixed",
+	}
+	for _, cursor := range []string{">", "👉", "→→"} {
+		for _, glyphs := range []Glyphs{DefaultGlyphs(), UnicodeGlyphs()} {
+			for _, icon := range []string{"", "*", "📁", "漢"} {
+				for _, mode := range []SelectionMode{SelectOne, SelectMany} {
+					items := make([]Option, len(tricky))
+					for i, label := range tricky {
+						items[i] = Option{Label: label, Icon: icon, Checked: i%2 == 0}
+					}
+					m := newColorModel(WithItems(items))
+					m.SetCursor(cursor)
+					m.Glyphs = glyphs
+					m.SelectionMode = mode
+					resize(&m, 24, 20)
+					press(&m, "down", "down")
+					for _, line := range strings.Split(m.View(), "\n") {
+						if w := stringWidth(line); w != 24 {
+							t.Errorf("cursor %q, glyphs %q, icon %q, mode %v: %q is %d cells wide, want 24",
+								cursor, glyphs.Checked, icon, mode, line, w)
+						}
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package options
+
+import "strings"
+
+// wrapping returns whether long options wrap onto more lines. That's only
+// done in the vertical layout, where each line of the list is a row, so min
+// is still the first line of the window. size is then the number of lines of
+// the view the window takes up, holding as many whole options from min as
+// fit, or the one at min alone, cut short, when it's taller than that.
+func (m Model) wrapping() bool {
+	return m.WrapLongOptions && m.Width > 0 && m.Layout == LayoutVertical
+}
+
+// lineSpan is the range of runes of a label shown on one line.
+type lineSpan struct {
+	start, end int
+}
+
+// wrapSpans breaks runes into lines of at most width cells, at spaces where
+// possible. The spaces lines are broken at are dropped.
+func wrapSpans(runes []rune, width int) []lineSpan {
+	if width < 1 {
+		width = 1
+	}
+	widths := runeWidths(runes)
+	var spans []lineSpan
+	for start := 0; start < len(runes); {
+		end, w, space := start, 0, -1
+		for ; end < len(runes); end++ {
+			rw := widths[end]
+			if w+rw > width && end > start {
+				break
+			}
+			if runes[end] == ' ' {
+				space = end
+			}
+			w += rw
+		}
+		if end < len(runes) && runes[end] != ' ' && space > start {
+			end = space
+		}
+		spans = append(spans, lineSpan{start, end})
+		start = end
+		if start < len(runes) && runes[start] == ' ' {
+			start++
+		}
+	}
+	if len(spans) == 0 {
+		spans = append(spans, lineSpan{})
+	}
+	return spans
+}
+
+// renderWrapped renders row r over as many lines as name needs for the row
+// to fit in width cells. Continuation lines are indented past the cursor and
+// prefix.
+func (m Model) renderWrapped(r, cursor int, name string, matches []int, prefix string, width int) string {
+	item := m.item(m.optionIndex(r))
+	sec, hint, secW := m.trailing(r, width, prefix, name)
+	avail := width - m.leadWidth(r, prefix) - secW
+	runes := []rune(name)
+	indent := strings.Repeat(" ", stringWidth(m.decoration(r, -1))+stringWidth(prefix))
+
+	var s strings.Builder
+	for j, sp := range wrapSpans(runes, avail) {
+		text := string(runes[sp.start:sp.end])
+		var ms []int
+		for _, k := range matches {
+			if k >= sp.start && k < sp.end {
+				ms = append(ms, k-sp.start)
+			}
+		}
+		if j == 0 {
+			line := m.withTrailing(r, cursor, m.renderLine(r, cursor, item, prefix, text, ms), sec, hint, width)
+			s.WriteString(m.fill(r, cursor, line, width))
+			continue
+		}
+		line := m.lead(r, "", indent) + m.labelView(r, cursor, text, ms)
+		s.WriteRune('\n')
+		s.WriteString(m.fill(r, cursor, line, width))
+	}
+	return s.String()
+}
+
+// rowHeight returns the number of lines row r takes up.
+func (m Model) rowHeight(r int) int {
+	if !m.wrapping() || m.optionIndex(r) == -1 {
+		return 1
+	}
+	width := m.rowWidth()
+	if m.ShowScrollbar {
+		width--
+	}
+	if m.RenderRow != nil {
+		return strings.Count(m.renderRow(r, m.cursorIndex(), width), "\n") + 1
+	}
+	name, _, prefix := m.rowText(r, m.Options[m.optionIndex(r)])
+	_, _, secW := m.trailing(r, width, prefix, name)
+	return len(wrapSpans([]rune(name), width-m.leadWidth(r, prefix)-secW))
+}
+
+// lastVisible returns the last row in the window.
+func (m Model) lastVisible() int {
+	if !m.wrapping() {
+		return m.windowMax()
+	}
+	lines, budget := 0, m.size
+	r := m.min
+	for ; r < m.rowCount(); r++ {
+		lines += m.rowHeight(r)
+		if lines > budget && r > m.min {
+			break
+		}
+	}
+	return r - 1
+}
+
+// followCursorLines scrolls a window of wrapped options so that the whole of
+// the option on the cursor is visible.
+func (m *Model) followCursorLines() {
+	if m.selected <= m.min {
+		m.min = m.selected
+		return
+	}
+	// Take in the rows above the cursor from the bottom up, as far as the
+	// window goes, so a jump only measures the rows it lands among.
+	r, lines := m.selected, m.rowHeight(m.selected)
+	for r > m.min {
+		h := m.rowHeight(r - 1)
+		if lines+h > m.size {
+			break
+		}
+		r, lines = r-1, lines+h
+	}
+	m.min = r
+}
@@ -0,0 +1,87 @@
+package options
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// striped returns whether row r is drawn with Styles.OptionAlt, which is the
+// case for every other row when the style is set.
+func (m Model) striped(r int) bool {
+	return r%2 == 1 && styled(m.Styles.OptionAlt)
+}
+
+// styleFor returns style as it applies to row r.
+func (m Model) styleFor(r int, style lipgloss.Style) lipgloss.Style {
+	if m.striped(r) {
+		return style.Copy().Inherit(m.Styles.OptionAlt)
+	}
+	return style
+}
+
+// lead returns the blank gutter followed by num and prefix for row r.
+func (m Model) lead(r int, num, prefix string) string {
+	if !m.striped(r) {
+		return m.blankGutter() + num + prefix
+	}
+	if num == "" {
+		return m.alt(m.blankGutter() + prefix)
+	}
+	return m.alt(m.blankGutter()) + num + m.alt(prefix)
+}
+
+// alt renders s, when it isn't empty, with Styles.OptionAlt.
+func (m Model) alt(s string) string {
+	if s == "" {
+		return ""
+	}
+	return m.Styles.OptionAlt.Render(s)
+}
+
+// fill pads line, a line of row r, to width cells when the row has a
+// background, so that it forms a solid bar across the whole row. That's the
+// case for the row on the cursor when Styles.Selected has a background, and
+// for striped rows when Styles.OptionAlt has one.
+func (m Model) fill(r, cursor int, line string, width int) string {
+	if width <= 0 {
+		return line
+	}
+	style := m.padStyle(r, cursor)
+	if _, ok := style.GetBackground().(lipgloss.NoColor); ok {
+		return line
+	}
+	if pad := width - stringWidth(line); pad > 0 {
+		line += style.Render(strings.Repeat(" ", pad))
+	}
+	return line
+}
+
+// padStyle returns the style of the blank space in row r.
+func (m Model) padStyle(r, cursor int) lipgloss.Style {
+	if r == cursor {
+		return m.rowStyle(r, cursor)
+	}
+	return m.styleFor(r, m.Styles.Option)
+}
+
+// withBackground returns style with the background of from, unless it has a
+// background of its own.
+func withBackground(style, from lipgloss.Style) lipgloss.Style {
+	if _, ok := style.GetBackground().(lipgloss.NoColor); !ok {
+		return style
+	}
+	if _, ok := from.GetBackground().(lipgloss.NoColor); ok {
+		return style
+	}
+	return style.Copy().Background(from.GetBackground())
+}
+
+// styled returns whether style changes the look of text.
+func styled(style lipgloss.Style) bool {
+	_, noFg := style.GetForeground().(lipgloss.NoColor)
+	_, noBg := style.GetBackground().(lipgloss.NoColor)
+	return !noFg || !noBg || style.GetBold() || style.GetItalic() ||
+		style.GetUnderline() || style.GetStrikethrough() || style.GetReverse() ||
+		style.GetBlink() || style.GetFaint()
+}
@@ -0,0 +1,45 @@
+package options
+
+import (
+	"strconv"
+	"strings"
+)
+
+// EnableZones wraps each row of the picker, its title and its status bar in
+// zones marked with mark, such as bubblezone's Mark, for a parent model to
+// tell which one a mouse event lands in. The zones are named by ZoneID, and
+// the title and status bar by prefix followed by "title" and "status". A nil
+// mark turns the zones off.
+func (m *Model) EnableZones(prefix string, mark func(id, s string) string) {
+	m.Invalidate()
+	m.zonePrefix, m.markZone = prefix, mark
+}
+
+// ZoneID returns the name of the zone of the option at index i of Options:
+// the prefix given to EnableZones followed by i.
+func (m Model) ZoneID(i int) string {
+	return m.zonePrefix + strconv.Itoa(i)
+}
+
+// ZoneIndex returns the index in Options of the option whose zone is named
+// id, or -1 if id isn't the zone of an option.
+func (m Model) ZoneIndex(id string) int {
+	s, ok := strings.CutPrefix(id, m.zonePrefix)
+	if !ok {
+		return -1
+	}
+	i, err := strconv.Atoi(s)
+	if err != nil || i < 0 || i >= len(m.Options) || strconv.Itoa(i) != s {
+		return -1
+	}
+	return i
+}
+
+// zone marks s as the zone named by the prefix followed by name, or returns
+// it as it is when zones are off.
+func (m Model) zone(name, s string) string {
+	if m.markZone == nil {
+		return s
+	}
+	return m.markZone(m.zonePrefix+name, s)
+}